@@ -0,0 +1,350 @@
+package sigparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MarshalABI returns the JSON ABI array for the given signatures, in the
+// format produced by the Solidity compiler.
+//
+// Signatures with UnknownKind are treated as functions. Alias types, such as
+// "uint", are expanded to their canonical names. The "internalType" field
+// is omitted for tuples, because the names of the structs they represent
+// are not known. An error is returned if any
+// parameter has a type that cannot be represented in the ABI, e.g. an
+// unresolved struct name, or if any signature is a modifier signature.
+func MarshalABI(sigs []Signature) ([]byte, error) {
+	abi := make([]jsonSignature, len(sigs))
+	for i, sig := range sigs {
+		var err error
+		if abi[i], err = toJSONSignature(sig); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(abi)
+}
+
+// ParseABIJSON parses a single JSON ABI fragment, in the format produced by
+// the Solidity compiler, e.g.
+// {"type":"function","name":"foo","inputs":[...],"outputs":[...]}.
+//
+// Tuples are read from the "components" field, and the "indexed" flags of
+// event inputs are preserved. The state mutability is stored in the
+// StateMutability field and as a modifier, except for "nonpayable", which is
+// the default and leaves the field UnspecifiedMutability. The
+// "anonymous" flag is stored in the Anonymous field. If the "type" field is
+// missing, the fragment is treated as a function.
+//
+// Unlike the other parsing functions, errors are not of the ParseError type.
+func ParseABIJSON(data []byte) (Signature, error) {
+	var js jsonSignature
+	if err := json.Unmarshal(data, &js); err != nil {
+		return Signature{}, err
+	}
+	return fromJSONSignature(js)
+}
+
+// ParseABI parses a JSON ABI array, e.g. the "abi" field of a compiler
+// artifact, and returns its entries as signatures, in the same order. Each
+// entry is converted as described in ParseABIJSON.
+func ParseABI(data []byte) ([]Signature, error) {
+	var abi []jsonSignature
+	if err := json.Unmarshal(data, &abi); err != nil {
+		return nil, err
+	}
+	sigs := make([]Signature, len(abi))
+	for i, js := range abi {
+		var err error
+		if sigs[i], err = fromJSONSignature(js); err != nil {
+			return nil, fmt.Errorf(`invalid ABI entry at index %d: %w`, i, err)
+		}
+	}
+	return sigs, nil
+}
+
+func fromJSONSignature(js jsonSignature) (Signature, error) {
+	var (
+		err error
+		sig Signature
+	)
+	switch js.Type {
+	case "function", "":
+		sig.Kind = FunctionKind
+	case "constructor":
+		sig.Kind = ConstructorKind
+	case "fallback":
+		sig.Kind = FallbackKind
+	case "receive":
+		sig.Kind = ReceiveKind
+	case "event":
+		sig.Kind = EventKind
+	case "error":
+		sig.Kind = ErrorKind
+	default:
+		return Signature{}, fmt.Errorf(`unknown ABI entry type %q`, js.Type)
+	}
+	switch sig.Kind {
+	case FunctionKind, EventKind, ErrorKind:
+		sig.Name = js.Name
+	}
+	if js.Inputs != nil {
+		if sig.Inputs, err = fromJSONParameters(*js.Inputs); err != nil {
+			return Signature{}, err
+		}
+	}
+	if js.Outputs != nil {
+		if sig.Outputs, err = fromJSONParameters(*js.Outputs); err != nil {
+			return Signature{}, err
+		}
+	}
+	switch js.StateMutability {
+	case "", "nonpayable":
+	case "view", "pure", "payable":
+		sig.Modifiers = append(sig.Modifiers, js.StateMutability)
+		sig.ParsedModifiers = append(sig.ParsedModifiers, Modifier{Name: js.StateMutability})
+		sig.StateMutability, _ = findStateMutability(sig.Modifiers)
+	default:
+		return Signature{}, fmt.Errorf(`unknown state mutability %q`, js.StateMutability)
+	}
+	if js.Anonymous != nil && *js.Anonymous {
+		if sig.Kind != EventKind {
+			return Signature{}, fmt.Errorf(`unexpected "anonymous" flag in %s`, sig.Kind)
+		}
+		sig.Anonymous = true
+	}
+	return sig, nil
+}
+
+func fromJSONParameters(jps []jsonParameter) ([]Parameter, error) {
+	if len(jps) == 0 {
+		return nil, nil
+	}
+	params := make([]Parameter, len(jps))
+	for i, jp := range jps {
+		var err error
+		if params[i], err = fromJSONParameter(jp); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+func fromJSONParameter(jp jsonParameter) (Parameter, error) {
+	var (
+		err   error
+		param = Parameter{Name: jp.Name}
+		typ   = jp.Type
+	)
+	if idx := strings.IndexByte(typ, '['); idx >= 0 {
+		if param.Arrays, err = ParseArrayDims(typ[idx:]); err != nil {
+			return Parameter{}, fmt.Errorf(`invalid ABI type %q: %v`, jp.Type, err)
+		}
+		typ = typ[:idx]
+	}
+	if typ == "tuple" {
+		param.Tuple = make([]Parameter, len(jp.Components))
+		for i, c := range jp.Components {
+			if param.Tuple[i], err = fromJSONParameter(c); err != nil {
+				return Parameter{}, err
+			}
+		}
+	} else {
+		if _, err := isDynamicElementaryType(typ); err != nil {
+			return Parameter{}, fmt.Errorf(`invalid ABI type %q: %v`, jp.Type, err)
+		}
+		param.Type = typ
+		param.Payable = typ == "address" && strings.HasPrefix(jp.InternalType, "address payable")
+	}
+	if jp.Indexed != nil {
+		param.Indexed = *jp.Indexed
+	}
+	return param, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The signature is
+// encoded as a JSON ABI fragment, in the same format as used by MarshalABI.
+func (s Signature) MarshalJSON() ([]byte, error) {
+	js, err := toJSONSignature(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(js)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The parameter is
+// encoded as a JSON ABI parameter, with tuple elements as "components".
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	jp, err := toJSONParameter(p)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jp)
+}
+
+// ToHumanReadableABI returns the human-readable ABI for the given
+// signatures, in the format used by the ethers library, e.g.
+// "function balanceOf(address owner) view returns (uint256)". Each
+// signature is formatted as FormatFragment does in FullFormat.
+//
+// Modifier signatures are not a part of the ABI, so they are skipped.
+func ToHumanReadableABI(sigs []Signature) []string {
+	abi := make([]string, 0, len(sigs))
+	for _, sig := range sigs {
+		if sig.Kind == ModifierKind {
+			continue
+		}
+		abi = append(abi, formatFragment(sig, true))
+	}
+	return abi
+}
+
+// jsonSignature is the JSON ABI representation of a signature.
+type jsonSignature struct {
+	Type            string           `json:"type"`
+	Name            string           `json:"name,omitempty"`
+	Inputs          *[]jsonParameter `json:"inputs,omitempty"`
+	Outputs         *[]jsonParameter `json:"outputs,omitempty"`
+	StateMutability string           `json:"stateMutability,omitempty"`
+	Anonymous       *bool            `json:"anonymous,omitempty"`
+}
+
+// jsonParameter is the JSON ABI representation of a parameter.
+type jsonParameter struct {
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	InternalType string          `json:"internalType,omitempty"`
+	Components   []jsonParameter `json:"components,omitempty"`
+	Indexed      *bool           `json:"indexed,omitempty"`
+}
+
+func toJSONSignature(sig Signature) (jsonSignature, error) {
+	var js jsonSignature
+	if sig.Kind == ModifierKind {
+		return jsonSignature{}, fmt.Errorf(`modifier %q cannot be represented in the ABI`, sig.Name)
+	}
+	inputs, err := toJSONParameters(sig.Inputs, sig.Kind == EventKind)
+	if err != nil {
+		return jsonSignature{}, err
+	}
+	outputs, err := toJSONParameters(sig.Outputs, false)
+	if err != nil {
+		return jsonSignature{}, err
+	}
+	switch sig.Kind {
+	case ConstructorKind:
+		js.Type = "constructor"
+		js.Inputs = &inputs
+		js.StateMutability = stateMutability(sig)
+	case FallbackKind:
+		js.Type = "fallback"
+		js.StateMutability = stateMutability(sig)
+	case ReceiveKind:
+		js.Type = "receive"
+		js.StateMutability = "payable"
+	case EventKind:
+		anonymous := sig.Anonymous
+		js.Type = "event"
+		js.Name = sig.Name
+		js.Inputs = &inputs
+		js.Anonymous = &anonymous
+	case ErrorKind:
+		js.Type = "error"
+		js.Name = sig.Name
+		js.Inputs = &inputs
+	default:
+		js.Type = "function"
+		js.Name = sig.Name
+		js.Inputs = &inputs
+		js.Outputs = &outputs
+		js.StateMutability = stateMutability(sig)
+	}
+	return js, nil
+}
+
+func toJSONParameters(params []Parameter, event bool) ([]jsonParameter, error) {
+	jps := make([]jsonParameter, len(params))
+	for i, param := range params {
+		var err error
+		if jps[i], err = toJSONParameter(param); err != nil {
+			return nil, err
+		}
+		if event {
+			indexed := param.Indexed
+			jps[i].Indexed = &indexed
+		}
+	}
+	return jps, nil
+}
+
+func toJSONParameter(param Parameter) (jsonParameter, error) {
+	jp := jsonParameter{Name: param.Name}
+	if len(param.Type) > 0 {
+		typ, err := canonicalElementaryType(param.Type)
+		if err != nil {
+			return jsonParameter{}, err
+		}
+		jp.Type = typ
+	} else {
+		jp.Type = "tuple"
+		jp.Components = make([]jsonParameter, len(param.Tuple))
+		for i, c := range param.Tuple {
+			var err error
+			if jp.Components[i], err = toJSONParameter(c); err != nil {
+				return jsonParameter{}, err
+			}
+		}
+	}
+	jp.Type += arraySuffix(param.Arrays)
+	// The internal type of a tuple is the name of the struct, e.g.
+	// "struct Order[]", which is not known here, so it is omitted.
+	if len(param.Type) > 0 {
+		jp.InternalType = jp.Type
+	}
+	if param.Payable {
+		jp.InternalType = "address payable" + arraySuffix(param.Arrays)
+	}
+	if param.Function != nil {
+		if param.Function.Visibility != External {
+			return jsonParameter{}, fmt.Errorf(`internal function type %q cannot be represented in the ABI`, param.Function.String())
+		}
+		jp.InternalType = param.Function.String() + arraySuffix(param.Arrays)
+	}
+	return jp, nil
+}
+
+// arraySuffix returns the string representation of array dimensions,
+// e.g. "[][2]".
+func arraySuffix(arrays []int) string {
+	var buf strings.Builder
+	for _, n := range arrays {
+		if n == -1 {
+			buf.WriteString("[]")
+		} else {
+			buf.WriteByte('[')
+			buf.WriteString(strconv.Itoa(n))
+			buf.WriteByte(']')
+		}
+	}
+	return buf.String()
+}
+
+// stateMutability returns the ABI state mutability of the signature.
+func stateMutability(sig Signature) string {
+	if m, ok := sig.declaredMutability(); ok {
+		return m.String()
+	}
+	return NonPayable.String()
+}
+
+// hasModifier returns true if modifiers contain the given modifier.
+func hasModifier(modifiers []string, modifier string) bool {
+	for _, mod := range modifiers {
+		if mod == modifier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,366 @@
+package sigparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalABI(t *testing.T) {
+	tests := []struct {
+		sigs    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			sigs: []string{"function transfer(address to, uint amount) external returns (bool)"},
+			want: `[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address","internalType":"address"},{"name":"amount","type":"uint256","internalType":"uint256"}],"outputs":[{"name":"","type":"bool","internalType":"bool"}],"stateMutability":"nonpayable"}]`,
+		},
+		{
+			sigs: []string{"foo()", "function bar() view", "function baz() pure", "function qux() payable"},
+			want: `[` +
+				`{"type":"function","name":"foo","inputs":[],"outputs":[],"stateMutability":"nonpayable"},` +
+				`{"type":"function","name":"bar","inputs":[],"outputs":[],"stateMutability":"view"},` +
+				`{"type":"function","name":"baz","inputs":[],"outputs":[],"stateMutability":"pure"},` +
+				`{"type":"function","name":"qux","inputs":[],"outputs":[],"stateMutability":"payable"}` +
+				`]`,
+		},
+		{
+			sigs: []string{
+				"constructor(string name)",
+				"fallback() external",
+				"receive() external payable",
+				"event Transfer(address indexed from, address indexed to, uint256 value)",
+				"event Log(string msg) anonymous",
+				"error Unauthorized(address caller)",
+			},
+			want: `[` +
+				`{"type":"constructor","inputs":[{"name":"name","type":"string","internalType":"string"}],"stateMutability":"nonpayable"},` +
+				`{"type":"fallback","stateMutability":"nonpayable"},` +
+				`{"type":"receive","stateMutability":"payable"},` +
+				`{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","internalType":"address","indexed":true},{"name":"to","type":"address","internalType":"address","indexed":true},{"name":"value","type":"uint256","internalType":"uint256","indexed":false}],"anonymous":false},` +
+				`{"type":"event","name":"Log","inputs":[{"name":"msg","type":"string","internalType":"string","indexed":false}],"anonymous":true},` +
+				`{"type":"error","name":"Unauthorized","inputs":[{"name":"caller","type":"address","internalType":"address"}]}` +
+				`]`,
+		},
+		{
+			sigs: []string{"function fill((address maker, (address token, uint256 amount)[] assets)[2] orders) returns (uint[] memory)"},
+			want: `[{"type":"function","name":"fill","inputs":[{"name":"orders","type":"tuple[2]","components":[{"name":"maker","type":"address","internalType":"address"},{"name":"assets","type":"tuple[]","components":[{"name":"token","type":"address","internalType":"address"},{"name":"amount","type":"uint256","internalType":"uint256"}]}]}],"outputs":[{"name":"","type":"uint256[]","internalType":"uint256[]"}],"stateMutability":"nonpayable"}]`,
+		},
+		{
+			sigs: []string{"function send(address payable to, address payable[] others)"},
+			want: `[{"type":"function","name":"send","inputs":[{"name":"to","type":"address","internalType":"address payable"},{"name":"others","type":"address[]","internalType":"address payable[]"}],"outputs":[],"stateMutability":"nonpayable"}]`,
+		},
+		{
+			sigs: nil,
+			want: `[]`,
+		},
+		{
+			sigs:    []string{"foo(Bar)"},
+			wantErr: true,
+		},
+		{
+			sigs:    []string{"foo()", "modifier onlyOwner()"},
+			wantErr: true,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sigs := make([]Signature, len(tt.sigs))
+			for i, s := range tt.sigs {
+				sigs[i] = mustParseSignature(t, s)
+			}
+			got, err := MarshalABI(sigs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MarshalABI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want && !tt.wantErr {
+				t.Errorf("MarshalABI() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToHumanReadableABI(t *testing.T) {
+	sigs := []string{
+		"function transfer(address to, uint amount) external returns (bool)",
+		"function balanceOf(address owner) public view returns (uint256)",
+		"function fill((address maker, (address token, uint256 amount)[] assets) memory order) payable",
+		"foo() constant returns (bytes memory)",
+		"constructor(string memory name) payable",
+		"fallback(bytes calldata) external returns (bytes memory)",
+		"receive() external payable",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Log(string msg) anonymous",
+		"error Unauthorized(address caller)",
+	}
+	want := []string{
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function fill(tuple(address maker, tuple(address token, uint256 amount)[] assets) order) payable",
+		"function foo() view returns (bytes)",
+		"constructor(string name) payable",
+		"fallback() external",
+		"receive() external payable",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Log(string msg) anonymous",
+		"error Unauthorized(address caller)",
+	}
+	parsed := make([]Signature, len(sigs))
+	for i, sig := range sigs {
+		parsed[i] = mustParseSignature(t, sig)
+	}
+	got := ToHumanReadableABI(parsed)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ToHumanReadableABI() got = %#v, want %#v", got, want)
+	}
+	// The human-readable ABI must describe the same JSON ABI.
+	reparsed := make([]Signature, len(got))
+	for i, sig := range got {
+		reparsed[i] = mustParseSignature(t, sig)
+	}
+	wantJSON, err := MarshalABI(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotJSON, err := MarshalABI(reparsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("MarshalABI(ToHumanReadableABI()) got = %s, want %s", gotJSON, wantJSON)
+	}
+	// Modifiers are not a part of the ABI.
+	got = ToHumanReadableABI([]Signature{mustParseSignature(t, "modifier onlyOwner()"), mustParseSignature(t, "foo()")})
+	if want := []string{"function foo()"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ToHumanReadableABI() got = %#v, want %#v", got, want)
+	}
+}
+
+func TestSignatureMarshalJSON(t *testing.T) {
+	tests := []struct {
+		sig     string
+		want    string
+		wantErr bool
+	}{
+		{
+			sig:  "function balanceOf(address owner) view returns (uint)",
+			want: `{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address","internalType":"address"}],"outputs":[{"name":"","type":"uint256","internalType":"uint256"}],"stateMutability":"view"}`,
+		},
+		{
+			sig:  "event Transfer(address indexed from, (uint a, bool b) c)",
+			want: `{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","internalType":"address","indexed":true},{"name":"c","type":"tuple","components":[{"name":"a","type":"uint256","internalType":"uint256"},{"name":"b","type":"bool","internalType":"bool"}],"indexed":false}],"anonymous":false}`,
+		},
+		{
+			sig:  "function foo(function(uint256) external view returns (bool) cb)",
+			want: `{"type":"function","name":"foo","inputs":[{"name":"cb","type":"function","internalType":"function(uint256) external view returns (bool)"}],"outputs":[],"stateMutability":"nonpayable"}`,
+		},
+		{
+			sig:     "foo(function(uint256) cb)",
+			wantErr: true,
+		},
+		{
+			sig:     "foo(Bar)",
+			wantErr: true,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := json.Marshal(mustParseSignature(t, tt.sig))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureMarshalJSONStateMutability(t *testing.T) {
+	tests := []struct {
+		sig  Signature
+		want string
+	}{
+		{sig: Signature{Kind: FunctionKind, Name: "foo"}, want: "nonpayable"},
+		{sig: Signature{Kind: FunctionKind, Name: "foo", StateMutability: Pure}, want: "pure"},
+		{sig: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"payable"}}, want: "payable"},
+		{sig: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"constant"}}, want: "view"},
+		{sig: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"view"}, StateMutability: Payable}, want: "payable"},
+		{sig: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"view"}, StateMutability: UnspecifiedMutability}, want: "view"},
+		{sig: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"view"}, StateMutability: NonPayable}, want: "nonpayable"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := json.Marshal(tt.sig)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			want := `{"type":"function","name":"foo","inputs":[],"outputs":[],"stateMutability":"` + tt.want + `"}`
+			if string(got) != want {
+				t.Errorf("json.Marshal() got = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestParameterMarshalJSON(t *testing.T) {
+	param, err := ParseParameter("(uint a, bytes[] memory b)[2] c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"c","type":"tuple[2]","components":[{"name":"a","type":"uint256","internalType":"uint256"},{"name":"b","type":"bytes[]","internalType":"bytes[]"}]}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() got = %s, want %s", got, want)
+	}
+}
+
+func TestParseABIJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Signature
+		wantErr bool
+	}{
+		{
+			json: `{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}`,
+			want: Signature{Kind: FunctionKind, Name: "balanceOf", Inputs: []Parameter{{Name: "owner", Type: "address"}}, Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"view"}, ParsedModifiers: []Modifier{{Name: "view"}}, StateMutability: View},
+		},
+		{
+			json: `{"name":"foo","inputs":[],"outputs":[]}`,
+			want: Signature{Kind: FunctionKind, Name: "foo"},
+		},
+		{
+			json: `{"type":"function","name":"fill","inputs":[{"name":"orders","type":"tuple[2][]","components":[{"name":"maker","type":"address","internalType":"address payable"},{"name":"assets","type":"tuple[]","components":[{"name":"amount","type":"uint256"}]}]}],"outputs":[],"stateMutability":"payable"}`,
+			want: Signature{
+				Kind: FunctionKind,
+				Name: "fill",
+				Inputs: []Parameter{{
+					Name:   "orders",
+					Arrays: []int{2, -1},
+					Tuple: []Parameter{
+						{Name: "maker", Type: "address", Payable: true},
+						{Name: "assets", Arrays: []int{-1}, Tuple: []Parameter{{Name: "amount", Type: "uint256"}}},
+					},
+				}},
+				Modifiers:       []string{"payable"},
+				ParsedModifiers: []Modifier{{Name: "payable"}},
+				StateMutability: Payable,
+			},
+		},
+		{
+			json: `{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":true}`,
+			want: Signature{Kind: EventKind, Name: "Transfer", Inputs: []Parameter{{Name: "from", Type: "address", Indexed: true}, {Name: "value", Type: "uint256"}}, Anonymous: true},
+		},
+		{
+			json: `{"type":"error","name":"Unauthorized","inputs":[{"name":"caller","type":"address"}]}`,
+			want: Signature{Kind: ErrorKind, Name: "Unauthorized", Inputs: []Parameter{{Name: "caller", Type: "address"}}},
+		},
+		{
+			json: `{"type":"constructor","inputs":[{"name":"name","type":"string"}],"stateMutability":"nonpayable"}`,
+			want: Signature{Kind: ConstructorKind, Inputs: []Parameter{{Name: "name", Type: "string"}}},
+		},
+		{
+			json: `{"type":"receive","stateMutability":"payable"}`,
+			want: Signature{Kind: ReceiveKind, Modifiers: []string{"payable"}, ParsedModifiers: []Modifier{{Name: "payable"}}, StateMutability: Payable},
+		},
+		{json: `{"type":"function","name":"foo","inputs":[{"name":"a","type":"Foo"}]}`, wantErr: true},
+		{json: `{"type":"function","name":"foo","inputs":[{"name":"a","type":"uint256[0]"}]}`, wantErr: true},
+		{json: `{"type":"function","name":"foo","inputs":[],"anonymous":true}`, wantErr: true},
+		{json: `{"type":"modifier","name":"foo"}`, wantErr: true},
+		{json: `{"type":"function","name":"foo","stateMutability":"constant"}`, wantErr: true},
+		{json: `[]`, wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseABIJSON([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseABIJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseABIJSON() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseABIJSONRoundTrip(t *testing.T) {
+	sigs := []string{
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"function fill((address maker, (address token, uint256 amount)[] assets)[2] orders) view returns (uint256[])",
+		"constructor(string name) payable",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Log(string msg) anonymous",
+		"error Unauthorized(address payable caller)",
+	}
+	for n, s := range sigs {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, s)
+			data, err := json.Marshal(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseABIJSON(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, sig) {
+				t.Errorf("ParseABIJSON(json.Marshal()) got = %v, want %v", got, sig)
+			}
+		})
+	}
+}
+
+func TestParseABI(t *testing.T) {
+	abi := `[
+		{"type":"constructor","inputs":[{"name":"name","type":"string","internalType":"string"}],"stateMutability":"nonpayable"},
+		{"type":"fallback","stateMutability":"payable"},
+		{"type":"receive","stateMutability":"payable"},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"},
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false},
+		{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+	]`
+	got, err := ParseABI([]byte(abi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"constructor(string name)",
+		"fallback() payable",
+		"receive() payable",
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseABI() returned %d signatures, want %d", len(got), len(want))
+	}
+	for i, sig := range got {
+		if !reflect.DeepEqual(sig, mustParseSignature(t, want[i])) {
+			t.Errorf("ParseABI()[%d] got = %v, want %v", i, sig, want[i])
+		}
+	}
+	// Human-readable ABI generated from the JSON ABI must describe the same
+	// interface.
+	wantHumanReadable := []string{
+		"constructor(string name)",
+		"fallback() external payable",
+		"receive() external payable",
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	}
+	if hr := ToHumanReadableABI(got); !reflect.DeepEqual(hr, wantHumanReadable) {
+		t.Errorf("ToHumanReadableABI(ParseABI()) got = %#v, want %#v", hr, wantHumanReadable)
+	}
+	if _, err := ParseABI([]byte(`[{"type":"function","name":"foo"},{"type":"foo"}]`)); err == nil || err.Error() != `invalid ABI entry at index 1: unknown ABI entry type "foo"` {
+		t.Errorf("ParseABI() error = %v", err)
+	}
+	if _, err := ParseABI([]byte(`{"type":"function","name":"foo"}`)); err == nil {
+		t.Errorf("ParseABI() expected error")
+	}
+}
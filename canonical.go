@@ -0,0 +1,137 @@
+package sigparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Canonical returns the canonical form of the signature, as used to compute
+// function selectors and event topics, e.g. "transfer(address,uint256)".
+//
+// The canonical form contains only the name and the input types. Parameter
+// names, data locations, indexed flags, modifiers and return values are
+// omitted, and alias types, such as "uint", are expanded to their canonical
+// names.
+func (s Signature) Canonical() string {
+	var buf strings.Builder
+	buf.WriteString(s.Name)
+	writeCanonicalTypes(&buf, s.Inputs)
+	return buf.String()
+}
+
+// CanonicalType returns the canonical ABI type of the parameter, e.g.
+// "uint256[]" for "uint[] memory a" or "(address,uint256)" for a tuple.
+// Names, data locations and indexed flags are omitted, and alias types are
+// expanded. Types that are not valid elementary types, such as unresolved
+// struct names, are returned as is.
+func (p Parameter) CanonicalType() string {
+	var buf strings.Builder
+	writeCanonicalType(&buf, p)
+	return buf.String()
+}
+
+// CanonicalStringV2 returns the canonical form of the signature followed by
+// the canonical output types, if there are any, e.g.
+// "balanceOf(address)(uint256)".
+//
+// Only the part returned by Canonical, i.e. the name and the input types,
+// affects the selector. The output types are appended for display purposes
+// only. By default, a single unnamed tuple output is rendered with its own
+// parentheses, e.g. "foo()((uint256,bool))". The WithFlattenSingleReturn
+// option renders it as "foo()(uint256,bool)" instead, as some tools do.
+func (s Signature) CanonicalStringV2(opts ...FormatOption) string {
+	var fo formatOptions
+	for _, opt := range opts {
+		opt(&fo)
+	}
+	outputs := s.Outputs
+	if fo.flattenSingleReturn && isSingleUnnamedTuple(outputs) {
+		outputs = outputs[0].Tuple
+	}
+	var buf strings.Builder
+	buf.WriteString(s.Canonical())
+	if len(outputs) > 0 {
+		writeCanonicalTypes(&buf, outputs)
+	}
+	return buf.String()
+}
+
+// EqualSelector returns true if both signatures have the same canonical
+// form, and thus the same selector. Alias types are expanded before
+// comparison, so "transfer(uint)" and "transfer(uint256)" are equal.
+// Parameter names, data locations, modifiers and return values are ignored.
+func EqualSelector(a, b Signature) bool {
+	return a.Canonical() == b.Canonical()
+}
+
+// DedupByCanonical returns the signatures without duplicates, keeping the
+// first occurrence of each. Signatures are duplicates if they are of the
+// same kind and have the same canonical form, so signatures that differ only
+// in parameter names, data locations, modifiers or return values are merged.
+// Signatures with UnknownKind are treated as functions.
+//
+// The returned signatures are the original ones, not their canonical forms.
+func DedupByCanonical(sigs []Signature) []Signature {
+	type key struct {
+		kind      SignatureKind
+		canonical string
+	}
+	var (
+		seen   = map[key]bool{}
+		unique []Signature
+	)
+	for _, sig := range sigs {
+		k := key{kind: sig.Kind, canonical: sig.Canonical()}
+		if k.kind == UnknownKind {
+			k.kind = FunctionKind
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, sig)
+	}
+	return unique
+}
+
+// ErrorCanonicalString returns the canonical form of a custom error
+// signature, which is the preimage of the error selector used in revert
+// data, e.g. "InsufficientBalance(uint256,uint256)".
+//
+// Unlike Canonical, it returns an error if the signature is not an error
+// signature. This prevents accidental use of a function selector where an
+// error selector is expected.
+func (s Signature) ErrorCanonicalString() (string, error) {
+	if s.Kind != ErrorKind {
+		return "", fmt.Errorf(`expected error signature, got %s`, s.Kind)
+	}
+	return s.Canonical(), nil
+}
+
+// writeCanonicalTypes writes the canonical types of params as
+// a parenthesized, comma separated list.
+func writeCanonicalTypes(buf *strings.Builder, params []Parameter) {
+	buf.WriteByte('(')
+	for i, p := range params {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalType(buf, p)
+	}
+	buf.WriteByte(')')
+}
+
+// writeCanonicalType writes the canonical type of p. Types that are not
+// valid elementary types are written as is.
+func writeCanonicalType(buf *strings.Builder, p Parameter) {
+	if len(p.Type) > 0 {
+		typ, err := canonicalElementaryType(p.Type)
+		if err != nil {
+			typ = p.Type
+		}
+		buf.WriteString(typ)
+	} else {
+		writeCanonicalTypes(buf, p.Tuple)
+	}
+	buf.WriteString(arraySuffix(p.Arrays))
+}
@@ -0,0 +1,189 @@
+package sigparser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/defiweb/go-sigparser/internal/keccak"
+)
+
+func TestSignatureCanonical(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want string
+	}{
+		{sig: "foo()", want: "foo()"},
+		{sig: "function transfer(address to, uint256 amount) external returns (bool)", want: "transfer(address,uint256)"},
+		{sig: "transfer(address,uint)", want: "transfer(address,uint256)"},
+		{sig: "foo(int a, byte b, fixed c, ufixed d)", want: "foo(int256,bytes1,fixed128x18,ufixed128x18)"},
+		{sig: "foo(bytes memory a, string calldata b)", want: "foo(bytes,string)"},
+		{sig: "event Transfer(address indexed from, address indexed to, uint value)", want: "Transfer(address,address,uint256)"},
+		{sig: "foo((uint a, (bool b)[] c)[2] d, uint[][3] e)", want: "foo((uint256,(bool)[])[2],uint256[][3])"},
+		{sig: "foo(Foo a)", want: "foo(Foo)"},
+		{sig: "transfer(address payable,uint256)", want: "transfer(address,uint256)"},
+		{sig: "foo((address payable a)[] memory b, address payable[2])", want: "foo((address)[],address[2])"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			if got := mustParseSignature(t, tt.sig).Canonical(); got != tt.want {
+				t.Errorf("Signature.Canonical() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterCanonicalType(t *testing.T) {
+	tests := []struct {
+		param string
+		want  string
+	}{
+		{param: "uint256", want: "uint256"},
+		{param: "uint a", want: "uint256"},
+		{param: "address payable to", want: "address"},
+		{param: "bytes memory data", want: "bytes"},
+		{param: "int[][2] calldata", want: "int256[][2]"},
+		{param: "(address a, uint b)[] memory c", want: "(address,uint256)[]"},
+		{param: "tuple(byte, (fixed, bool)[1])", want: "(bytes1,(fixed128x18,bool)[1])"},
+		{param: "()", want: "()"},
+		{param: "Foo[] foo", want: "Foo[]"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			param, err := ParseParameter(tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := param.CanonicalType(); got != tt.want {
+				t.Errorf("Parameter.CanonicalType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureCanonicalStringV2(t *testing.T) {
+	tests := []struct {
+		sig  string
+		opts []FormatOption
+		want string
+	}{
+		{sig: "foo()", want: "foo()"},
+		{sig: "balanceOf(address owner) view returns (uint balance)", want: "balanceOf(address)(uint256)"},
+		{sig: "foo(uint a) returns (bytes memory, bool)", want: "foo(uint256)(bytes,bool)"},
+		{sig: "foo() returns ((uint a, bool b))", want: "foo()((uint256,bool))"},
+		{sig: "foo() returns ((uint a, bool b))", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo()(uint256,bool)"},
+		{sig: "foo() returns ((uint a, bool b)[])", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo()((uint256,bool)[])"},
+		{sig: "foo((uint a, bool b)) returns (uint)", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo((uint256,bool))(uint256)"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			if got := sig.CanonicalStringV2(tt.opts...); got != tt.want {
+				t.Errorf("Signature.CanonicalStringV2() = %v, want %v", got, tt.want)
+			}
+			if got := sig.CanonicalStringV2(tt.opts...); got[:len(sig.Canonical())] != sig.Canonical() {
+				t.Errorf("Signature.CanonicalStringV2() = %v, does not start with %v", got, sig.Canonical())
+			}
+		})
+	}
+}
+
+func TestSignatureErrorCanonicalString(t *testing.T) {
+	tests := []struct {
+		sig          string
+		want         string
+		wantSelector string
+		wantErr      bool
+	}{
+		{sig: "error Error(string)", want: "Error(string)", wantSelector: "08c379a0"},
+		{sig: "error Panic(uint code)", want: "Panic(uint256)", wantSelector: "4e487b71"},
+		{sig: "error ERC20InsufficientBalance(address sender, uint256 balance, uint256 needed)", want: "ERC20InsufficientBalance(address,uint256,uint256)", wantSelector: "e450d38c"},
+		{sig: "error OwnableUnauthorizedAccount(address account)", want: "OwnableUnauthorizedAccount(address)", wantSelector: "118cdaa7"},
+		{sig: "function Error(string)", wantErr: true},
+		{sig: "Error(string)", wantErr: true},
+		{sig: "event Error(string)", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := mustParseSignature(t, tt.sig).ErrorCanonicalString()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Signature.ErrorCanonicalString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Signature.ErrorCanonicalString() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			hash := keccak.Sum256([]byte(got))
+			if sel := hex.EncodeToString(hash[:4]); sel != tt.wantSelector {
+				t.Errorf("selector = %v, want %v", sel, tt.wantSelector)
+			}
+		})
+	}
+}
+
+func TestEqualSelector(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "transfer(uint)", b: "transfer(uint256)", want: true},
+		{a: "foo(int)", b: "foo(int256)", want: true},
+		{a: "foo(byte)", b: "foo(bytes1)", want: true},
+		{a: "foo(fixed)", b: "foo(fixed128x18)", want: true},
+		{a: "foo(ufixed[])", b: "foo(ufixed128x18[])", want: true},
+		{a: "foo((uint a, byte b) c)", b: "function foo((uint256, bytes1) memory) external returns (bool)", want: true},
+		{a: "foo(uint8)", b: "foo(uint256)", want: false},
+		{a: "foo(uint)", b: "bar(uint)", want: false},
+		{a: "foo(uint[])", b: "foo(uint[1])", want: false},
+		{a: "foo(bytes)", b: "foo(bytes1)", want: false},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			a, b := mustParseSignature(t, tt.a), mustParseSignature(t, tt.b)
+			if got := EqualSelector(a, b); got != tt.want {
+				t.Errorf("EqualSelector() = %v, want %v", got, tt.want)
+			}
+			if got := EqualSelector(b, a); got != tt.want {
+				t.Errorf("EqualSelector() is not symmetric")
+			}
+		})
+	}
+}
+
+func TestDedupByCanonical(t *testing.T) {
+	tests := []struct {
+		sigs []string
+		want []string
+	}{
+		{sigs: nil, want: nil},
+		{
+			sigs: []string{"transfer(address to, uint256 amount)", "transfer(address dst, uint wad) returns (bool)", "function transfer(address, uint256)"},
+			want: []string{"transfer(address to, uint256 amount)"},
+		},
+		{
+			sigs: []string{"foo(uint256 a)", "foo(int256 a)", "bar(uint256 b)", "foo(uint a)"},
+			want: []string{"foo(uint256 a)", "foo(int256 a)", "bar(uint256 b)"},
+		},
+		{
+			sigs: []string{"function Foo(uint256 a)", "event Foo(uint256 a)", "error Foo(uint256 a)", "event Foo(uint256 indexed b)"},
+			want: []string{"function Foo(uint256 a)", "event Foo(uint256 a)", "error Foo(uint256 a)"},
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			var sigs, want []Signature
+			for _, s := range tt.sigs {
+				sigs = append(sigs, mustParseSignature(t, s))
+			}
+			for _, s := range tt.want {
+				want = append(want, mustParseSignature(t, s))
+			}
+			if got := DedupByCanonical(sigs); !reflect.DeepEqual(got, want) {
+				t.Errorf("DedupByCanonical() got = %v, want %v", got, want)
+			}
+		})
+	}
+}
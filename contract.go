@@ -0,0 +1,61 @@
+package sigparser
+
+// ParseContract parses a Solidity contract declaration and returns its
+// externally visible interface, e.g. for:
+//
+//	contract Token is ERC20 {
+//	    uint256 private fee;
+//
+//	    event FeeChanged(uint256 fee);
+//
+//	    function setFee(uint256 newFee) external onlyOwner {
+//	        fee = newFee;
+//	        emit FeeChanged(newFee);
+//	    }
+//
+//	    function _burn(address from) internal {}
+//	}
+//
+// the returned interface contains the FeeChanged event and the setFee
+// function. Abstract contracts are supported as well.
+//
+// Function bodies, modifier definitions, state variables, enums and other
+// declarations are skipped. Functions, constructors, fallback and receive
+// functions are returned unless they are declared internal or private.
+// Getters of public state variables are not included. Events and errors
+// are always returned. Struct references in the signatures are resolved
+// as described in ResolveStructs.
+//
+// Like ParseInterface, the declaration may be preceded by pragma directives
+// and import statements, and comments are allowed anywhere whitespace is
+// allowed. Errors are ParseErrors describing the position in the source at
+// which they occurred.
+func ParseContract(src string, opts ...Option) (Interface, error) {
+	return parseDeclaration(src, "contract", opts)
+}
+
+// headerEnd returns the position of the opening brace of the function body,
+// or the end of the input if there is no body. Braces inside parentheses
+// and comments are ignored.
+func (p *parser) headerEnd() int {
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	depth := 0
+	for p.hasNext() {
+		if p.parseComment() {
+			continue
+		}
+		switch p.peek() {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '{':
+			if depth == 0 {
+				return p.pos
+			}
+		}
+		p.read()
+	}
+	return p.pos
+}
@@ -0,0 +1,156 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseContract(t *testing.T) {
+	order := []Parameter{{Type: "address", Name: "maker"}, {Type: "uint256", Name: "amount"}}
+	tests := []struct {
+		src     string
+		want    Interface
+		wantErr string
+	}{
+		{
+			src:  "contract Empty {}",
+			want: Interface{Name: "Empty", Structs: map[string]Parameter{}},
+		},
+		{
+			src: `// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import {ERC20} from "./ERC20.sol";
+
+contract Token is ERC20("Token", "TKN"), Ownable {
+    using SafeMath for uint256;
+
+    enum State { Active, Paused }
+
+    uint256 private fee = 10;
+    mapping(address => uint256) public balances;
+    string constant NAME = "{;";
+
+    event FeeChanged(uint256 fee);
+    error Paused();
+
+    modifier whenActive() {
+        require(state == State.Active, "paused");
+        _;
+    }
+
+    constructor(uint256 initialFee) {
+        fee = initialFee;
+    }
+
+    receive() external payable {}
+
+    /// @notice Sets the fee. }
+    function setFee(uint256 newFee) external onlyRole(keccak256("ADMIN")) {
+        if (newFee > 100) {
+            revert("fee too high }");
+        }
+        fee = newFee;
+        emit FeeChanged(newFee);
+    }
+
+    function getFee() public view returns (uint256) { return fee; }
+
+    function _burn(address from) internal {}
+    function _check() private view {}
+}
+`,
+			want: Interface{
+				Name:  "Token",
+				Bases: []string{"ERC20", "Ownable"},
+				Signatures: []Signature{
+					{Kind: EventKind, Name: "FeeChanged", Inputs: []Parameter{{Type: "uint256", Name: "fee"}}},
+					{Kind: ErrorKind, Name: "Paused"},
+					{Kind: ConstructorKind, Inputs: []Parameter{{Type: "uint256", Name: "initialFee"}}},
+					{Kind: ReceiveKind, Modifiers: []string{"external", "payable"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}}, StateMutability: Payable, Visibility: External},
+					{
+						Kind:      FunctionKind,
+						Name:      "setFee",
+						Inputs:    []Parameter{{Type: "uint256", Name: "newFee"}},
+						Modifiers: []string{"external", "onlyRole"},
+						ParsedModifiers: []Modifier{
+							{Name: "external"},
+							{Name: "onlyRole", Args: []string{`keccak256("ADMIN")`}},
+						},
+						Visibility: External,
+					},
+					{
+						Kind:            FunctionKind,
+						Name:            "getFee",
+						Outputs:         []Parameter{{Type: "uint256"}},
+						Modifiers:       []string{"public", "view"},
+						ParsedModifiers: []Modifier{{Name: "public"}, {Name: "view"}},
+						Visibility:      Public,
+						StateMutability: View,
+					},
+				},
+				Structs: map[string]Parameter{},
+			},
+		},
+		{
+			src: "abstract contract Exchange {\n\tstruct Order { address maker; uint256 amount; }\n\tfunction fill(Order calldata o) external virtual;\n}",
+			want: Interface{
+				Name: "Exchange",
+				Signatures: []Signature{
+					{
+						Kind:            FunctionKind,
+						Name:            "fill",
+						Inputs:          []Parameter{{Name: "o", Tuple: order, DataLocation: CallData}},
+						Modifiers:       []string{"external", "virtual"},
+						ParsedModifiers: []Modifier{{Name: "external"}, {Name: "virtual"}},
+						Visibility:      External,
+					},
+				},
+				Structs: map[string]Parameter{"Order": {Name: "Order", Tuple: order}},
+			},
+		},
+		{
+			src: "contract Callback {\n\tfunction(uint256) external callback;\n\tfunction (bool) internal pure check = _check;\n\tfunction call() external {}\n}",
+			want: Interface{
+				Name: "Callback",
+				Signatures: []Signature{
+					{Kind: FunctionKind, Name: "call", Modifiers: []string{"external"}, ParsedModifiers: []Modifier{{Name: "external"}}, Visibility: External},
+				},
+				Structs: map[string]Parameter{},
+			},
+		},
+		{
+			src:     "interface IFoo {}",
+			wantErr: `unexpected character 'i', 'contract' keyword expected`,
+		},
+		{
+			src:     "contract Foo {\n\tfunction foo() external {\n",
+			wantErr: `unclosed '{'`,
+		},
+		{
+			src:     "contract Foo {}\ncontract Bar {}",
+			wantErr: `unexpected input after contract declaration`,
+		},
+		{
+			src:     "contract Foo {\n\tfunction foo(uint256 a external {}\n}",
+			wantErr: `invalid definition: unexpected character 'e', ',' or ')' expected`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseContract(tt.src)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseContract() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseContract() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseContract() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package crypto provides selector and topic computations for parsed
+// signatures.
+//
+// The package uses a built-in Keccak-256 implementation, so it does not
+// depend on any third-party cryptographic library.
+package crypto
+
+import (
+	"fmt"
+
+	"github.com/defiweb/go-sigparser"
+	"github.com/defiweb/go-sigparser/internal/keccak"
+)
+
+// Keccak256 returns the Keccak-256 hash of the concatenated data.
+func Keccak256(data ...[]byte) [32]byte {
+	return keccak.Sum256(data...)
+}
+
+// Selector returns the 4-byte selector of the signature, computed as the
+// first four bytes of the Keccak-256 hash of its canonical form. It is the
+// same as sigparser.Signature.Selector, so the zero value is returned for
+// signatures that have no selector, such as constructors.
+func Selector(sig sigparser.Signature) [4]byte {
+	return sig.Selector()
+}
+
+// CallDataPrefix returns the 4-byte selector of a function signature as
+// a byte slice, ready to be prepended to ABI-encoded arguments. Signatures
+// with UnknownKind are treated as functions.
+//
+// An error is returned if the signature is not a function signature.
+func CallDataPrefix(sig sigparser.Signature) ([]byte, error) {
+	if sig.Kind != sigparser.FunctionKind && sig.Kind != sigparser.UnknownKind {
+		return nil, fmt.Errorf(`expected function signature, got %s`, sig.Kind)
+	}
+	sel := Selector(sig)
+	return sel[:], nil
+}
+
+// VerifySelector parses the signature and checks that its selector is equal
+// to the given one. It is useful for validating data from signature
+// databases, where each signature is stored along with its selector.
+//
+// An error is returned if the signature cannot be parsed or if the selectors
+// do not match.
+func VerifySelector(sigStr string, selector [4]byte) (sigparser.Signature, error) {
+	sig, err := sigparser.ParseSignature(sigStr)
+	if err != nil {
+		return sigparser.Signature{}, err
+	}
+	if sel := Selector(sig); sel != selector {
+		return sigparser.Signature{}, fmt.Errorf(`selector mismatch: computed 0x%x claimed 0x%x`, sel[:], selector[:])
+	}
+	return sig, nil
+}
+
+// DecodeInfo parses the signature and returns its canonical form, its
+// selector as a 0x-prefixed hex string and its kind. For events, the full
+// 32-byte topic is returned instead of the selector. Constructor, fallback
+// and receive signatures have no selector, so an empty string is returned
+// for them.
+func DecodeInfo(sigStr string) (canonical string, selector string, kind sigparser.SignatureKind, err error) {
+	sig, err := sigparser.ParseSignature(sigStr)
+	if err != nil {
+		return "", "", sigparser.UnknownKind, err
+	}
+	canonical = sig.Canonical()
+	switch sig.Kind {
+	case sigparser.UnknownKind, sigparser.FunctionKind, sigparser.ErrorKind:
+		sel := Selector(sig)
+		selector = fmt.Sprintf("0x%x", sel[:])
+	case sigparser.EventKind:
+		selector = fmt.Sprintf("0x%x", Keccak256([]byte(canonical)))
+	}
+	return canonical, selector, sig.Kind, nil
+}
+
+// Selectors returns a map from canonical signature to selector for every
+// function and error in a human-readable ABI. Each element of abi is either
+// a signature or a struct definition. Struct definitions are used to resolve
+// struct types used in signatures, regardless of their position in the
+// list. Events, constructors, fallback and receive functions are skipped.
+func Selectors(abi []string) (map[string][4]byte, error) {
+	var (
+		sigs    []sigparser.Signature
+		structs = map[string]sigparser.Parameter{}
+	)
+	for i, s := range abi {
+		if sigparser.Kind(s).IsStruct() {
+			str, err := sigparser.ParseStruct(s)
+			if err != nil {
+				return nil, fmt.Errorf(`invalid struct at index %d: %w`, i, err)
+			}
+			structs[str.Name] = str
+			continue
+		}
+		sig, err := sigparser.ParseSignature(s)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid signature at index %d: %w`, i, err)
+		}
+		sigs = append(sigs, sig)
+	}
+	m := make(map[string][4]byte, len(sigs))
+	for _, sig := range sigs {
+		switch sig.Kind {
+		case sigparser.UnknownKind, sigparser.FunctionKind, sigparser.ErrorKind:
+		default:
+			continue
+		}
+		sig, err := sigparser.ResolveStructs(sig, structs)
+		if err != nil {
+			return nil, err
+		}
+		m[sig.Canonical()] = Selector(sig)
+	}
+	return m, nil
+}
+
+// SelectorMap returns a map from selector to signature for all function and
+// error signatures in sigs. Signatures with UnknownKind are treated as
+// functions. Other kinds are skipped.
+//
+// If the same canonical signature appears more than once with the same kind,
+// the first one is used. An error is returned if two different signatures
+// have the same selector, and also if a function and an error have the same
+// canonical form, e.g. "function Foo(uint256)" and "error Foo(uint256)",
+// because their selectors are the same as well.
+func SelectorMap(sigs []sigparser.Signature) (map[[4]byte]sigparser.Signature, error) {
+	m := make(map[[4]byte]sigparser.Signature, len(sigs))
+	for _, sig := range sigs {
+		switch sig.Kind {
+		case sigparser.UnknownKind, sigparser.FunctionKind, sigparser.ErrorKind:
+		default:
+			continue
+		}
+		sel := Selector(sig)
+		if prev, ok := m[sel]; ok {
+			if prev.Canonical() != sig.Canonical() {
+				return nil, fmt.Errorf(`selector collision 0x%x between %q and %q`, sel, prev.Canonical(), sig.Canonical())
+			}
+			if selectorKind(prev) != selectorKind(sig) {
+				return nil, fmt.Errorf(`selector collision 0x%x between %s %q and %s %q`, sel, selectorKind(prev), prev.Canonical(), selectorKind(sig), sig.Canonical())
+			}
+			continue
+		}
+		m[sel] = sig
+	}
+	return m, nil
+}
+
+// selectorKind returns the kind of the signature used to detect selector
+// collisions. Signatures with UnknownKind are treated as functions.
+func selectorKind(sig sigparser.Signature) sigparser.SignatureKind {
+	if sig.Kind == sigparser.UnknownKind {
+		return sigparser.FunctionKind
+	}
+	return sig.Kind
+}
@@ -0,0 +1,254 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/defiweb/go-sigparser"
+)
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		sig  string
+		opts []sigparser.Option
+		want string
+	}{
+		{sig: "transfer(address,uint256)", want: "a9059cbb"},
+		{sig: "function transfer(address to, uint amount) external returns (bool)", want: "a9059cbb"},
+		{sig: "balanceOf(address)", want: "70a08231"},
+		{sig: "IERC20.transfer(address,uint256)", opts: []sigparser.Option{sigparser.WithScopedNames()}, want: "a9059cbb"},
+		{sig: "transfer(address payable,uint256)", want: "a9059cbb"},
+		{sig: "function IERC20.balanceOf(address) view returns (uint256)", opts: []sigparser.Option{sigparser.WithScopedNames()}, want: "70a08231"},
+		{sig: "error Error(string)", want: "08c379a0"},
+		{sig: "error Panic(uint256)", want: "4e487b71"},
+		{sig: "constructor(uint256)", want: "00000000"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sel := Selector(mustParseSignature(t, tt.sig, tt.opts...))
+			if got := hex.EncodeToString(sel[:]); got != tt.want {
+				t.Errorf("Selector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallDataPrefix(t *testing.T) {
+	tests := []struct {
+		sig     string
+		want    string
+		wantErr bool
+	}{
+		{sig: "transfer(address,uint256)", want: "a9059cbb"},
+		{sig: "function approve(address spender, uint256 amount) external returns (bool)", want: "095ea7b3"},
+		{sig: "event Transfer(address,address,uint256)", wantErr: true},
+		{sig: "error Error(string)", wantErr: true},
+		{sig: "constructor(uint256)", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := CallDataPrefix(mustParseSignature(t, tt.sig))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CallDataPrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != 4 {
+				t.Fatalf("CallDataPrefix() len = %d, want 4", len(got))
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("CallDataPrefix() = %x, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMap(t *testing.T) {
+	sigs := []sigparser.Signature{
+		mustParseSignature(t, "function transfer(address to, uint256 amount)"),
+		mustParseSignature(t, "balanceOf(address)"),
+		mustParseSignature(t, "error Error(string)"),
+		mustParseSignature(t, "event Transfer(address indexed from, address indexed to, uint256 value)"),
+		mustParseSignature(t, "constructor(uint256 a)"),
+		mustParseSignature(t, "transfer(address,uint)"), // duplicate of the first one
+	}
+	m, err := SelectorMap(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 3 {
+		t.Fatalf("SelectorMap() len = %d, want 3", len(m))
+	}
+	for _, tt := range []struct {
+		sel  [4]byte
+		name string
+	}{
+		{sel: [4]byte{0xa9, 0x05, 0x9c, 0xbb}, name: "transfer"},
+		{sel: [4]byte{0x70, 0xa0, 0x82, 0x31}, name: "balanceOf"},
+		{sel: [4]byte{0x08, 0xc3, 0x79, 0xa0}, name: "Error"},
+	} {
+		if got, ok := m[tt.sel]; !ok || got.Name != tt.name {
+			t.Errorf("SelectorMap()[%x] = %v, want %v", tt.sel, got, tt.name)
+		}
+	}
+	if got := m[[4]byte{0xa9, 0x05, 0x9c, 0xbb}]; got.Inputs[0].Name != "to" {
+		t.Errorf("SelectorMap() must keep the first occurrence, got %v", got)
+	}
+
+	// Known selector collision.
+	_, err = SelectorMap([]sigparser.Signature{
+		mustParseSignature(t, "transferFrom(address,address,uint256)"),
+		mustParseSignature(t, "gasprice_bit_ether(int128)"),
+	})
+	if err == nil {
+		t.Errorf("SelectorMap() expected collision error")
+	}
+
+	// A function and an error with the same canonical form.
+	_, err = SelectorMap([]sigparser.Signature{
+		mustParseSignature(t, "Foo(uint256)"),
+		mustParseSignature(t, "error Foo(uint256 a)"),
+	})
+	if want := `selector collision 0x1176bd96 between function "Foo(uint256)" and error "Foo(uint256)"`; err == nil || err.Error() != want {
+		t.Errorf("SelectorMap() error = %v, want %v", err, want)
+	}
+}
+
+func mustParseSignature(t *testing.T, s string, opts ...sigparser.Option) sigparser.Signature {
+	sig, err := sigparser.ParseSignature(s, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestVerifySelector(t *testing.T) {
+	tests := []struct {
+		sig      string
+		selector [4]byte
+		wantErr  string
+	}{
+		{sig: "transfer(address,uint256)", selector: [4]byte{0xa9, 0x05, 0x9c, 0xbb}},
+		{sig: "function transfer(address to, uint amount) returns (bool)", selector: [4]byte{0xa9, 0x05, 0x9c, 0xbb}},
+		{sig: "balanceOf(address)", selector: [4]byte{0xa9, 0x05, 0x9c, 0xbb}, wantErr: `selector mismatch: computed 0x70a08231 claimed 0xa9059cbb`},
+		{sig: "transfer(address,", selector: [4]byte{0xa9, 0x05, 0x9c, 0xbb}, wantErr: `unclosed '('`},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig, err := VerifySelector(tt.sig, tt.selector)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("VerifySelector() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifySelector() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(sig, mustParseSignature(t, tt.sig)) {
+				t.Errorf("VerifySelector() got = %v, want %v", sig, tt.sig)
+			}
+		})
+	}
+}
+
+func TestDecodeInfo(t *testing.T) {
+	tests := []struct {
+		sig           string
+		wantCanonical string
+		wantSelector  string
+		wantKind      sigparser.SignatureKind
+		wantErr       bool
+	}{
+		{
+			sig:           "transfer(address to, uint amount)",
+			wantCanonical: "transfer(address,uint256)",
+			wantSelector:  "0xa9059cbb",
+			wantKind:      sigparser.UnknownKind,
+		},
+		{
+			sig:           "function balanceOf(address) view returns (uint256)",
+			wantCanonical: "balanceOf(address)",
+			wantSelector:  "0x70a08231",
+			wantKind:      sigparser.FunctionKind,
+		},
+		{
+			sig:           "event Transfer(address indexed from, address indexed to, uint256 value)",
+			wantCanonical: "Transfer(address,address,uint256)",
+			wantSelector:  "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			wantKind:      sigparser.EventKind,
+		},
+		{
+			sig:           "error Error(string)",
+			wantCanonical: "Error(string)",
+			wantSelector:  "0x08c379a0",
+			wantKind:      sigparser.ErrorKind,
+		},
+		{
+			sig:           "constructor(uint256 a)",
+			wantCanonical: "(uint256)",
+			wantKind:      sigparser.ConstructorKind,
+		},
+		{
+			sig:     "foo(",
+			wantErr: true,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			canonical, selector, kind, err := DecodeInfo(tt.sig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if canonical != tt.wantCanonical {
+				t.Errorf("DecodeInfo() canonical = %v, want %v", canonical, tt.wantCanonical)
+			}
+			if selector != tt.wantSelector {
+				t.Errorf("DecodeInfo() selector = %v, want %v", selector, tt.wantSelector)
+			}
+			if kind != tt.wantKind {
+				t.Errorf("DecodeInfo() kind = %v, want %v", kind, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	abi := []string{
+		"function fill(Order order, uint256 fee) external returns (bool)",
+		"struct Order { address maker; Asset[] assets; }",
+		"struct Asset { address token; uint256 amount; }",
+		"function transfer(address to, uint amount) external returns (bool)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+		"constructor(string name)",
+	}
+	got, err := Selectors(abi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][4]byte{
+		"fill((address,(address,uint256)[]),uint256)": selectorOf("fill((address,(address,uint256)[]),uint256)"),
+		"transfer(address,uint256)":                   {0xa9, 0x05, 0x9c, 0xbb},
+		"InsufficientBalance(uint256,uint256)":        selectorOf("InsufficientBalance(uint256,uint256)"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Selectors() got = %v, want %v", got, want)
+	}
+	if _, err := Selectors([]string{"foo(", "bar()"}); err == nil {
+		t.Errorf("Selectors() expected error")
+	}
+	if _, err := Selectors([]string{"struct Foo { uint256 a }"}); err == nil {
+		t.Errorf("Selectors() expected error")
+	}
+}
+
+func selectorOf(canonical string) [4]byte {
+	var sel [4]byte
+	hash := Keccak256([]byte(canonical))
+	copy(sel[:], hash[:4])
+	return sel
+}
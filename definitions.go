@@ -0,0 +1,296 @@
+package sigparser
+
+import "fmt"
+
+// ParseABIDefinitions parses a list of struct definitions and signatures,
+// e.g. an interface pasted from a Solidity source file, and resolves struct
+// references in the signatures.
+//
+// Besides signatures and struct definitions, the input may contain
+// user-defined value types, e.g. "type Price is uint256", which are
+// replaced with their underlying types in the signatures.
+//
+// Definitions are separated by semicolons or new lines. A definition may
+// span multiple lines, e.g. "foo()\n    returns (uint256)". It ends at a new
+// line only if the next line starts with a keyword, such as "function" or
+// "struct", or with a name immediately followed by '(', e.g.
+// "bar(uint256)". Because of that, modifier invocations with arguments
+// cannot be placed on a separate line. Structs may be used before they are
+// defined.
+//
+// The returned signatures are in the order in which they appear in the
+// input, with struct types replaced by tuples as described in
+// ResolveStructs. The returned map contains the struct definitions keyed
+// by struct name, in which user-defined value types are replaced with their
+// underlying types.
+//
+// Errors are ParseErrors describing the position in the input at which they
+// occurred.
+func ParseABIDefinitions(input string, opts ...Option) ([]Signature, map[string]Parameter, error) {
+	p := NewParser(opts...)
+	p.Reset(input)
+	sigs, structs, types, err := p.p.parseDefinitions(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, str := range structs {
+		structs[name] = resolveTypes(str, types)
+	}
+	return sigs, structs, nil
+}
+
+// parseDefinitions parses struct definitions and signatures until the end
+// of the input and resolves struct references in the signatures.
+//
+// If source is true, the input is treated as the body of a Solidity
+// contract or interface: definitions are delimited as described in
+// nextDefinition, function bodies are ignored, declarations other than
+// structs and signatures are skipped, and so are internal and private
+// functions.
+//
+// The returned maps contain the struct definitions and the user-defined
+// value types keyed by name. The map of types is nil if there are none.
+func (p *parser) parseDefinitions(source bool) ([]Signature, map[string]Parameter, map[string]Parameter, error) {
+	var (
+		sigs    []Signature
+		offsets []int
+		structs = map[string]Parameter{}
+		types   map[string]Parameter
+	)
+	for {
+		start, end := p.nextDefinition(source)
+		if start == end {
+			break
+		}
+		def := p.definitionParser(start, end)
+		kw := def.p.peekName()
+		if source && !isSourceDeclaration(kw) {
+			continue
+		}
+		if source && kw == "function" {
+			// Skip legacy unnamed fallback functions and function type
+			// variables, e.g. "function(uint256) external callback;".
+			def.p.parseName()
+			def.p.parseWhitespace()
+			if def.p.peekByte('(') {
+				continue
+			}
+			def.p.pos = 0
+		}
+		if kw == "struct" {
+			str, err := def.ParseStruct()
+			if err != nil {
+				return nil, nil, nil, definitionError(p.in, start, `invalid definition`, err)
+			}
+			if _, ok := types[str.Name]; ok {
+				return nil, nil, nil, newParseError(p.in, start, fmt.Errorf(`duplicate type %q`, str.Name))
+			}
+			if _, ok := structs[str.Name]; ok {
+				return nil, nil, nil, newParseError(p.in, start, fmt.Errorf(`duplicate struct %q`, str.Name))
+			}
+			structs[str.Name] = str
+			continue
+		}
+		if kw == "type" {
+			typ, err := def.ParseUserDefinedType()
+			if err != nil {
+				return nil, nil, nil, definitionError(p.in, start, `invalid definition`, err)
+			}
+			if _, ok := structs[typ.Name]; ok {
+				return nil, nil, nil, newParseError(p.in, start, fmt.Errorf(`duplicate type %q`, typ.Name))
+			}
+			if _, ok := types[typ.Name]; ok {
+				return nil, nil, nil, newParseError(p.in, start, fmt.Errorf(`duplicate type %q`, typ.Name))
+			}
+			if types == nil {
+				types = map[string]Parameter{}
+			}
+			types[typ.Name] = typ
+			continue
+		}
+		if source {
+			def.p.in = def.p.in[:def.p.headerEnd()]
+		}
+		sig, err := def.ParseSignature()
+		if err != nil {
+			return nil, nil, nil, definitionError(p.in, start, `invalid definition`, err)
+		}
+		if source && (sig.Visibility == Internal || sig.Visibility == Private) {
+			continue
+		}
+		sigs = append(sigs, sig)
+		offsets = append(offsets, start)
+	}
+	for i, sig := range sigs {
+		var err error
+		if sigs[i], err = resolveSignature(sig, structs, types); err != nil {
+			return nil, nil, nil, definitionError(p.in, offsets[i], `invalid definition`, err)
+		}
+	}
+	return sigs, structs, types, nil
+}
+
+// isSourceDeclaration returns true if the keyword starts a declaration that
+// is parsed from contract and interface bodies.
+func isSourceDeclaration(keyword string) bool {
+	switch keyword {
+	case "struct", "type", "function", "constructor", "fallback", "receive", "event", "error":
+		return true
+	}
+	return false
+}
+
+// nextDefinition skips leading whitespaces and delimiters and returns the
+// boundaries of the next definition. Semicolons and new lines end
+// a definition unless they are inside parentheses or braces. A new line
+// ends a definition only if the next line starts a new definition, as
+// described in nextLineStartsDefinition, so that definitions may span
+// multiple lines, e.g. "foo()\n    returns (uint256)". A definition that
+// starts with a keyword also ends at a closing brace at the top level. If
+// source is true, new lines do not end a definition, but a closing brace at
+// the top level does, so that struct definitions and function bodies need
+// no trailing semicolon, and delimiters inside string literals are
+// ignored. If there are no definitions left, start is equal to end.
+func (p *parser) nextDefinition(source bool) (start, end int) {
+	for {
+		p.parseWhitespace()
+		if !p.readByte(';') {
+			break
+		}
+	}
+	start = p.pos
+	depth := 0
+	keyword := isDefinitionKeyword(p.peekName())
+	for p.hasNext() {
+		if p.opts.comments && p.parseComment() {
+			continue
+		}
+		if source && p.skipString() {
+			continue
+		}
+		switch p.peek() {
+		case '(', '{':
+			depth++
+		case ')', '}':
+			if depth > 0 {
+				depth--
+			}
+			if (source || keyword) && depth == 0 && p.peek() == '}' {
+				p.read()
+				return start, p.pos
+			}
+		case ';':
+			if depth == 0 {
+				return start, p.pos
+			}
+		case '\n':
+			if depth == 0 && !source && p.nextLineStartsDefinition() {
+				return start, p.pos
+			}
+		}
+		p.read()
+	}
+	return start, p.pos
+}
+
+// nextLineStartsDefinition returns true if the line that follows the new line
+// at the current position starts a new definition, i.e. it starts with
+// a definition keyword or with a name immediately followed by '(', like
+// "transfer(". Names of modifier keywords and "returns" are not considered
+// as such, because they continue the definition. Empty lines and, if
+// enabled, comments are skipped.
+func (p *parser) nextLineStartsDefinition() bool {
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	p.parseWhitespace()
+	name := string(p.parseName())
+	if isDefinitionKeyword(name) {
+		return true
+	}
+	switch {
+	case len(name) == 0, name == "returns", name == "override", isModifierKeyword(name):
+		return false
+	}
+	return p.peekByte('(')
+}
+
+// isDefinitionKeyword returns true if the keyword starts a struct definition,
+// a user-defined value type or a signature.
+func isDefinitionKeyword(keyword string) bool {
+	switch keyword {
+	case "struct", "type", "function", "constructor", "fallback", "receive", "event", "error", "modifier":
+		return true
+	}
+	return false
+}
+
+// ParseSignatures parses a list of signatures separated by semicolons or new
+// lines, e.g. the contents of a file with one signature per line. Empty
+// lines are skipped. A signature may span multiple lines, as described in
+// ParseABIDefinitions.
+//
+// Unlike ParseABIDefinitions, struct definitions are not allowed. Errors
+// are ParseErrors, whose Line field contains the line at which they
+// occurred.
+func ParseSignatures(input string, opts ...Option) ([]Signature, error) {
+	pp := NewParser(opts...)
+	pp.Reset(input)
+	p := &pp.p
+	var sigs []Signature
+	for {
+		start, end := p.nextDefinition(false)
+		if start == end {
+			break
+		}
+		def := p.definitionParser(start, end)
+		sig, err := def.ParseSignature()
+		if err != nil {
+			return nil, definitionError(p.in, start, `invalid signature`, err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// ParseStructs parses a list of struct definitions and returns them in the
+// order in which they appear in the input, with references to other structs
+// in the list replaced by tuples, as described in ResolveStructs. Structs
+// may be used before they are defined.
+//
+// Definitions may be separated by whitespaces, semicolons or new lines.
+// Errors are ParseErrors describing the position in the input at which they
+// occurred.
+func ParseStructs(input string, opts ...Option) ([]Parameter, error) {
+	pp := NewParser(opts...)
+	pp.Reset(input)
+	p := &pp.p
+	var (
+		list    []Parameter
+		offsets []int
+		structs = map[string]Parameter{}
+	)
+	for {
+		start, end := p.nextDefinition(true)
+		if start == end {
+			break
+		}
+		def := p.definitionParser(start, end)
+		str, err := def.ParseStruct()
+		if err != nil {
+			return nil, definitionError(p.in, start, `invalid definition`, err)
+		}
+		if _, ok := structs[str.Name]; ok {
+			return nil, newParseError(p.in, start, fmt.Errorf(`duplicate struct %q`, str.Name))
+		}
+		structs[str.Name] = str
+		list = append(list, str)
+		offsets = append(offsets, start)
+	}
+	for i, str := range list {
+		var err error
+		if list[i], err = resolveStruct(str, structs, nil); err != nil {
+			return nil, definitionError(p.in, offsets[i], `invalid definition`, err)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,305 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseABIDefinitions(t *testing.T) {
+	pair := Parameter{Name: "Pair", Tuple: []Parameter{{Type: "address", Name: "a"}, {Type: "address", Name: "b"}}}
+	tests := []struct {
+		input       string
+		opts        []Option
+		wantSigs    []Signature
+		wantStructs map[string]Parameter
+		wantErr     string
+	}{
+		{
+			input:       "",
+			wantStructs: map[string]Parameter{},
+		},
+		{
+			input: "foo(uint256)\nbar(address);baz()",
+			wantSigs: []Signature{
+				{Name: "foo", Inputs: []Parameter{{Type: "uint256"}}},
+				{Name: "bar", Inputs: []Parameter{{Type: "address"}}},
+				{Name: "baz"},
+			},
+			wantStructs: map[string]Parameter{},
+		},
+		{
+			input: "function swap(Pair memory p) returns (uint256);\n\nstruct Pair {\n\taddress a;\n\taddress b;\n}\nevent Swapped(Pair p);",
+			wantSigs: []Signature{
+				{
+					Kind:    FunctionKind,
+					Name:    "swap",
+					Inputs:  []Parameter{{Name: "p", Tuple: pair.Tuple, DataLocation: Memory}},
+					Outputs: []Parameter{{Type: "uint256"}},
+				},
+				{
+					Kind:   EventKind,
+					Name:   "Swapped",
+					Inputs: []Parameter{{Name: "p", Tuple: pair.Tuple}},
+				},
+			},
+			wantStructs: map[string]Parameter{"Pair": pair},
+		},
+		{
+			input: "function foo(\n\tuint256 a,\n\tuint256 b\n) returns (uint256)",
+			wantSigs: []Signature{
+				{
+					Kind:    FunctionKind,
+					Name:    "foo",
+					Inputs:  []Parameter{{Type: "uint256", Name: "a"}, {Type: "uint256", Name: "b"}},
+					Outputs: []Parameter{{Type: "uint256"}},
+				},
+			},
+			wantStructs: map[string]Parameter{},
+		},
+		{
+			input: "function foo(uint256 a)\n\texternal\n\treturns (uint256)\nfunction bar()\n\tonlyOwner\nbaz()",
+			wantSigs: []Signature{
+				{
+					Kind:            FunctionKind,
+					Name:            "foo",
+					Inputs:          []Parameter{{Type: "uint256", Name: "a"}},
+					Outputs:         []Parameter{{Type: "uint256"}},
+					Modifiers:       []string{"external"},
+					ParsedModifiers: []Modifier{{Name: "external"}},
+					Visibility:      External,
+				},
+				{Kind: FunctionKind, Name: "bar", Modifiers: []string{"onlyOwner"}, ParsedModifiers: []Modifier{{Name: "onlyOwner"}}},
+				{Name: "baz"},
+			},
+			wantStructs: map[string]Parameter{},
+		},
+		{
+			input: "foo(uint256); // a; b\nbar() /* (; */",
+			opts:  []Option{WithComments()},
+			wantSigs: []Signature{
+				{Name: "foo", Inputs: []Parameter{{Type: "uint256"}}},
+				{Name: "bar"},
+			},
+			wantStructs: map[string]Parameter{},
+		},
+		{
+			input: "type Price is uint256\nstruct Quote { Price bid; Price[] asks; }\nfunction quote(Quote q) returns (Price)",
+			wantSigs: []Signature{
+				{
+					Kind:    FunctionKind,
+					Name:    "quote",
+					Inputs:  []Parameter{{Name: "q", Tuple: []Parameter{{Type: "uint256", Name: "bid"}, {Type: "uint256", Name: "asks", Arrays: []int{-1}}}}},
+					Outputs: []Parameter{{Type: "uint256"}},
+				},
+			},
+			wantStructs: map[string]Parameter{
+				"Quote": {Name: "Quote", Tuple: []Parameter{{Type: "uint256", Name: "bid"}, {Type: "uint256", Name: "asks", Arrays: []int{-1}}}},
+			},
+		},
+		{
+			input:   "type Price is uint256\ntype Price is uint128",
+			wantErr: `duplicate type "Price"`,
+		},
+		{
+			input:   "type Price is uint256\nstruct Price { uint256 a; }",
+			wantErr: `duplicate type "Price"`,
+		},
+		{
+			input:   "type Price is string",
+			wantErr: `invalid definition: invalid underlying type, elementary value type expected`,
+		},
+		{
+			input:   "foo();\nbar(uint256",
+			wantErr: `invalid definition: unclosed '('`,
+		},
+		{
+			input:   "struct A { uint256 a; }\nstruct A { uint256 b; }",
+			wantErr: `duplicate struct "A"`,
+		},
+		{
+			input:   "foo(A);struct A { B b; };struct B { A a; }",
+			wantErr: `invalid definition: recursive struct "A": A -> B -> A`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sigs, structs, err := ParseABIDefinitions(tt.input, tt.opts...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseABIDefinitions() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseABIDefinitions() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(sigs, tt.wantSigs) {
+				t.Errorf("ParseABIDefinitions() signatures = %#v, want %#v", sigs, tt.wantSigs)
+			}
+			if !reflect.DeepEqual(structs, tt.wantStructs) {
+				t.Errorf("ParseABIDefinitions() structs = %#v, want %#v", structs, tt.wantStructs)
+			}
+		})
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	tests := []struct {
+		input   string
+		opts    []Option
+		want    []Signature
+		wantErr string
+	}{
+		{input: ""},
+		{input: "\n\n;\n"},
+		{
+			input: "transfer(address,uint256)\n\nfunction balanceOf(address) view returns (uint256); event Foo(uint256)\n",
+			want: []Signature{
+				{Name: "transfer", Inputs: []Parameter{{Type: "address"}, {Type: "uint256"}}},
+				{Kind: FunctionKind, Name: "balanceOf", Inputs: []Parameter{{Type: "address"}}, Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"view"}, ParsedModifiers: []Modifier{{Name: "view"}}, StateMutability: View},
+				{Kind: EventKind, Name: "Foo", Inputs: []Parameter{{Type: "uint256"}}},
+			},
+		},
+		{
+			input: "foo(\n\tuint256 a,\n\tuint256 b\n)\r\nbar()",
+			want: []Signature{
+				{Name: "foo", Inputs: []Parameter{{Type: "uint256", Name: "a"}, {Type: "uint256", Name: "b"}}},
+				{Name: "bar"},
+			},
+		},
+		{
+			input: "event Foo(uint256 a)\n\tanonymous\nfoo()",
+			want: []Signature{
+				{Kind: EventKind, Name: "Foo", Inputs: []Parameter{{Type: "uint256", Name: "a"}}, Anonymous: true},
+				{Name: "foo"},
+			},
+		},
+		{
+			input: "foo(uint256)\nreturns (uint256)\nbar() external\n\tview",
+			want: []Signature{
+				{Name: "foo", Inputs: []Parameter{{Type: "uint256"}}, Outputs: []Parameter{{Type: "uint256"}}},
+				{Name: "bar", Modifiers: []string{"external", "view"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}}, Visibility: External, StateMutability: View},
+			},
+		},
+		{
+			input: "foo() // first\nbar() /* second */",
+			opts:  []Option{WithComments()},
+			want:  []Signature{{Name: "foo"}, {Name: "bar"}},
+		},
+		{
+			input:   "foo()\n\nbar(uint256\n",
+			wantErr: `invalid signature: unclosed '('`,
+		},
+		{
+			input:   "foo(); bar(\n\tuint256 a,\n\tuint256 b)\nbaz(uint256 a b)",
+			wantErr: `invalid signature: unexpected character 'b', ',' or ')' expected`,
+		},
+		{
+			input:   "foo()\nstruct A { uint256 a; }",
+			wantErr: `invalid signature: unexpected character '{' at the end of the signature`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseSignatures(tt.input, tt.opts...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseSignatures() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSignatures() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSignatures() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStructs(t *testing.T) {
+	point := []Parameter{{Type: "uint256", Name: "x"}, {Type: "uint256", Name: "y"}}
+	tests := []struct {
+		input   string
+		opts    []Option
+		want    []Parameter
+		wantErr string
+	}{
+		{input: ""},
+		{
+			input: "struct Point { uint256 x; uint256 y; }\nstruct Line {\n\tPoint a;\n\tPoint[] b;\n}",
+			want: []Parameter{
+				{Name: "Point", Tuple: point},
+				{Name: "Line", Tuple: []Parameter{{Name: "a", Tuple: point}, {Name: "b", Tuple: point, Arrays: []int{-1}}}},
+			},
+		},
+		{
+			input: "struct Line { Point a; Unknown b; } struct Point { uint256 x; uint256 y; }",
+			want: []Parameter{
+				{Name: "Line", Tuple: []Parameter{{Name: "a", Tuple: point}, {Type: "Unknown", Name: "b"}}},
+				{Name: "Point", Tuple: point},
+			},
+		},
+		{
+			input: "struct Point { uint256 x; uint256 y; }\nstruct Path { (Point p, uint256 w)[] steps; }",
+			want: []Parameter{
+				{Name: "Point", Tuple: point},
+				{Name: "Path", Tuple: []Parameter{
+					{Name: "steps", Tuple: []Parameter{{Name: "p", Tuple: point}, {Type: "uint256", Name: "w"}}, Arrays: []int{-1}},
+				}},
+			},
+		},
+		{
+			input:   "struct A { uint256 a; }\nfoo()",
+			wantErr: `invalid definition: unexpected character 'f', 'struct' keyword expected`,
+		},
+		{
+			input:   "struct A { uint256 a; }\nstruct A { uint256 b; }",
+			wantErr: `duplicate struct "A"`,
+		},
+		{
+			input:   "struct A { B b; }\nstruct B { A a; }",
+			wantErr: `invalid definition: recursive struct "A": A -> B -> A`,
+		},
+		{
+			input:   "struct A { (uint256, bool)[] pairs; }",
+			opts:    []Option{WithRequireTupleFieldNames()},
+			wantErr: `invalid definition: tuple field at index 0 requires a name`,
+		},
+		{
+			input:   "struct A { (uint256 indexed a) b; }",
+			wantErr: `invalid definition: unexpected indexed flag in tuple element`,
+		},
+		{
+			input:   "struct A { (bytes calldata a) b; }",
+			wantErr: `invalid definition: unexpected data location "calldata" in tuple element`,
+		},
+		{
+			input:   "struct A { uint256 [] values; }",
+			wantErr: `invalid definition: unexpected character '[', field name expected`,
+		},
+		{
+			input:   "struct Node { Node[] children; }",
+			wantErr: `invalid definition: recursive struct "Node": Node -> Node`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseStructs(tt.input, tt.opts...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseStructs() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStructs() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStructs() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EncodeType returns the EIP-712 encodeType string of the primary struct,
+// e.g. "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+//
+// Structs must be definitions as returned by ParseStruct. The primary struct
+// is encoded first, followed by the structs it references, directly or
+// transitively, sorted by name. Referenced structs must be present either
+// in the structs list or be the primary struct itself. Elementary types are
+// written in their canonical form, e.g. "uint" becomes "uint256".
+//
+// An error is returned if a referenced struct is not found, if the structs
+// list contains two different structs with the same name or if a field is an
+// inline tuple, which cannot be represented in EIP-712.
+func EncodeType(primary Parameter, structs ...Parameter) (string, error) {
+	defs := map[string]Parameter{}
+	for _, str := range structs {
+		if prev, ok := defs[str.Name]; ok && !reflect.DeepEqual(prev, str) {
+			return "", fmt.Errorf(`duplicate struct %q`, str.Name)
+		}
+		defs[str.Name] = str
+	}
+	defs[primary.Name] = primary
+	deps := map[string]bool{}
+	if err := collectStructDeps(primary, defs, deps); err != nil {
+		return "", err
+	}
+	delete(deps, primary.Name)
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf strings.Builder
+	writeEncodedStruct(&buf, primary)
+	for _, name := range names {
+		writeEncodedStruct(&buf, defs[name])
+	}
+	return buf.String(), nil
+}
+
+// collectStructDeps adds the names of the structs referenced by str to deps.
+func collectStructDeps(str Parameter, defs map[string]Parameter, deps map[string]bool) error {
+	if len(str.Name) == 0 || len(str.Type) > 0 {
+		return fmt.Errorf(`invalid struct definition %q`, str.Name)
+	}
+	for _, field := range str.Tuple {
+		if len(field.Type) == 0 {
+			return fmt.Errorf(`field %q of struct %q is an inline tuple, which is not supported by EIP-712`, field.Name, str.Name)
+		}
+		if _, err := canonicalElementaryType(field.Type); err == nil {
+			continue
+		}
+		dep, ok := defs[field.Type]
+		if !ok {
+			return fmt.Errorf(`unknown type %q of field %q in struct %q`, field.Type, field.Name, str.Name)
+		}
+		if deps[field.Type] {
+			continue
+		}
+		deps[field.Type] = true
+		if err := collectStructDeps(dep, defs, deps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEncodedStruct writes the encoding of a single struct, e.g.
+// "Person(string name,address wallet)".
+func writeEncodedStruct(buf *strings.Builder, str Parameter) {
+	buf.WriteString(str.Name)
+	buf.WriteByte('(')
+	for i, field := range str.Tuple {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalType(buf, Parameter{Type: field.Type, Arrays: field.Arrays})
+		buf.WriteByte(' ')
+		buf.WriteString(field.Name)
+	}
+	buf.WriteByte(')')
+}
+
+// TypeHash returns the EIP-712 typeHash of the struct, computed as the hash
+// of its encodeType string. Structs referenced by str must be provided in
+// the structs list, see EncodeType. The hash function must be Keccak-256
+// for the result to match the type hashes used by Ethereum.
+func TypeHash(str Parameter, hash HashFunc, structs ...Parameter) ([32]byte, error) {
+	enc, err := EncodeType(str, structs...)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hash([]byte(enc)), nil
+}
@@ -0,0 +1,113 @@
+package sigparser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/defiweb/go-sigparser/internal/keccak"
+)
+
+func TestEncodeType(t *testing.T) {
+	person := mustParseStruct(t, "struct Person { string name; address wallet; }")
+	mail := mustParseStruct(t, "struct Mail { Person from; Person[] to; string contents; Attachment[2] attachments; }")
+	attachment := mustParseStruct(t, "struct Attachment { bytes32 hash; Person owner; uint size; }")
+	node := mustParseStruct(t, "struct Node { uint256 value; Node[] children; }")
+	tests := []struct {
+		primary Parameter
+		structs []Parameter
+		want    string
+		wantErr string
+	}{
+		{
+			primary: person,
+			want:    "Person(string name,address wallet)",
+		},
+		{
+			primary: mail,
+			structs: []Parameter{person, attachment},
+			want:    "Mail(Person from,Person[] to,string contents,Attachment[2] attachments)Attachment(bytes32 hash,Person owner,uint256 size)Person(string name,address wallet)",
+		},
+		{
+			primary: attachment,
+			structs: []Parameter{mail, person, attachment},
+			want:    "Attachment(bytes32 hash,Person owner,uint256 size)Person(string name,address wallet)",
+		},
+		{
+			primary: node,
+			want:    "Node(uint256 value,Node[] children)",
+		},
+		{
+			primary: mail,
+			structs: []Parameter{person},
+			wantErr: `unknown type "Attachment" of field "attachments" in struct "Mail"`,
+		},
+		{
+			primary: person,
+			structs: []Parameter{person, mustParseStruct(t, "struct Person { string name; }")},
+			wantErr: `duplicate struct "Person"`,
+		},
+		{
+			primary: Parameter{Name: "Pair", Tuple: []Parameter{{Name: "p", Tuple: []Parameter{{Type: "uint256"}}}}},
+			wantErr: `field "p" of struct "Pair" is an inline tuple, which is not supported by EIP-712`,
+		},
+		{
+			primary: Parameter{Type: "uint256"},
+			wantErr: `invalid struct definition ""`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := EncodeType(tt.primary, tt.structs...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("EncodeType() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EncodeType() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EncodeType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeHash(t *testing.T) {
+	person := mustParseStruct(t, "struct Person { string name; address wallet; }")
+	mail := mustParseStruct(t, "struct Mail { Person from; Person to; string contents; }")
+	tests := []struct {
+		str     Parameter
+		structs []Parameter
+		want    string
+		wantErr bool
+	}{
+		{str: person, want: "b9d8c78acf9b987311de6c7b45bb6a9c8e1bf361fa7fd3467a2163f994c79500"},
+		{str: mail, structs: []Parameter{person}, want: "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"},
+		{str: mail, wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := TypeHash(tt.str, keccak.Sum256, tt.structs...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TypeHash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if hex.EncodeToString(got[:]) != tt.want {
+				t.Errorf("TypeHash() = %x, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParseStruct(t *testing.T, s string) Parameter {
+	str, err := ParseStruct(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return str
+}
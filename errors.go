@@ -0,0 +1,128 @@
+package sigparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ParseError is the error returned by the parsing functions. Besides the
+// error message, it describes where in the input the error was detected,
+// so that the location can be reported, e.g. by highlighting it in an
+// editor.
+//
+// The message returned by the Error method is the message of the
+// underlying error. It does not repeat the position, which is available in
+// the fields instead.
+//
+// The JSON ABI functions, ParseABIJSON and ParseABI, are an exception: they
+// return the errors of the encoding/json package or plain errors, because
+// their input does not use the signature syntax.
+type ParseError struct {
+	// Input is the parsed input.
+	Input string
+
+	// Offset is the byte offset in the input at which the error was
+	// detected. It is equal to the length of the input if the error was
+	// detected at the end of the input.
+	Offset int
+
+	// Line and Column are the 1-based line and column numbers of the
+	// offset. The column is counted in runes rather than bytes, so that it
+	// matches the position shown by text editors for non-ASCII inputs.
+	Line   int
+	Column int
+
+	// Token is the identifier or, if there is no identifier, the single
+	// character at the offset. It is empty at the end of the input.
+	Token string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError returns a ParseError for the error detected at the given
+// offset of the input.
+func newParseError(in []byte, offset int, err error) *ParseError {
+	if offset > len(in) {
+		offset = len(in)
+	}
+	line, column := lineColumn(in, offset)
+	return &ParseError{
+		Input:  string(in),
+		Offset: offset,
+		Line:   line,
+		Column: column,
+		Token:  tokenAt(in, offset),
+		Err:    err,
+	}
+}
+
+// parseError returns err as a ParseError. If err is a ParseError already,
+// e.g. returned by a nested parser that shares the input with p, a copy
+// with the Input set to the whole input of p is returned. Other errors are
+// reported at the current position.
+func (p *parser) parseError(err error) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return newParseError(p.in, pe.Offset, pe.Err)
+	}
+	return newParseError(p.in, p.pos, err)
+}
+
+// errorAt returns err as a ParseError detected at the given position, e.g.
+// at the opening parenthesis of an unclosed tuple.
+func (p *parser) errorAt(pos int, err error) error {
+	return newParseError(p.in, pos, err)
+}
+
+// definitionError returns a ParseError for an error in the definition that
+// starts at the given offset of the input. If err is a ParseError of the
+// parser of the definition, its offset is converted to the offset in the
+// input. Otherwise, the error is reported at the start of the definition.
+// The message is prefixed with the given prefix, e.g. "invalid definition".
+func definitionError(in []byte, start int, prefix string, err error) error {
+	offset := start
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		offset += pe.Offset
+		err = pe.Err
+	}
+	return newParseError(in, offset, fmt.Errorf(`%s: %w`, prefix, err))
+}
+
+// lineColumn returns the 1-based line and column numbers of the given
+// offset. The column is counted in runes.
+func lineColumn(in []byte, offset int) (line, column int) {
+	line = bytes.Count(in[:offset], []byte{'\n'}) + 1
+	start := bytes.LastIndexByte(in[:offset], '\n') + 1
+	return line, utf8.RuneCount(in[start:offset]) + 1
+}
+
+// tokenAt returns the identifier or the single character at the given
+// offset.
+func tokenAt(in []byte, offset int) string {
+	if offset >= len(in) {
+		return ""
+	}
+	end := offset
+	for end < len(in) && (isAlpha(in[end]) || isDigit(in[end]) || isIdentifierSymbol(in[end])) {
+		end++
+	}
+	if end == offset {
+		_, size := utf8.DecodeRune(in[offset:])
+		end += size
+	}
+	return string(in[offset:end])
+}
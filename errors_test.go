@@ -0,0 +1,143 @@
+package sigparser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		parse      func(string) error
+		input      string
+		wantOffset int
+		wantLine   int
+		wantColumn int
+		wantToken  string
+		wantMsg    string
+	}{
+		{
+			parse:      func(s string) error { _, err := ParseSignature(s); return err },
+			input:      "foo(uint256 a, !)",
+			wantOffset: 15,
+			wantLine:   1,
+			wantColumn: 16,
+			wantToken:  "!",
+			wantMsg:    `unexpected character '!', type expected`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseSignature(s); return err },
+			input:      "foo(uint256 a",
+			wantOffset: 3,
+			wantLine:   1,
+			wantColumn: 4,
+			wantToken:  "(",
+			wantMsg:    `unclosed '('`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseParameter(s); return err },
+			input:      "uint256[size]",
+			wantOffset: 8,
+			wantLine:   1,
+			wantColumn: 9,
+			wantToken:  "size",
+			wantMsg:    `array size must be a number, got "size"`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseStruct(s); return err },
+			input:      "struct A { uint256 a }",
+			wantOffset: 21,
+			wantLine:   1,
+			wantColumn: 22,
+			wantToken:  "}",
+			wantMsg:    `unexpected character '}', ';' expected`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseSignatures(s); return err },
+			input:      "foo()\nbar(!)",
+			wantOffset: 10,
+			wantLine:   2,
+			wantColumn: 5,
+			wantToken:  "!",
+			wantMsg:    `invalid signature: unexpected character '!', type expected`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseInterface(s); return err },
+			input:      "interface I {\n  function foo(uint256 x, ) external;\n}",
+			wantOffset: 40,
+			wantLine:   2,
+			wantColumn: 27,
+			wantToken:  ")",
+			wantMsg:    `invalid definition: unexpected character ')', type expected`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseContract(s); return err },
+			input:      "contract C { function f() {",
+			wantOffset: 11,
+			wantLine:   1,
+			wantColumn: 12,
+			wantToken:  "{",
+			wantMsg:    `unclosed '{'`,
+		},
+		{
+			parse:      func(s string) error { _, _, err := ParseABIDefinitions(s); return err },
+			input:      "struct A { int a; }\nstruct A { int b; }",
+			wantOffset: 20,
+			wantLine:   2,
+			wantColumn: 1,
+			wantToken:  "struct",
+			wantMsg:    `duplicate struct "A"`,
+		},
+		{
+			parse:      func(s string) error { _, err := ExtractSignatures(s); return err },
+			input:      "contract C { function f(uint x,) external {} }",
+			wantOffset: 31,
+			wantLine:   1,
+			wantColumn: 32,
+			wantToken:  ")",
+			wantMsg:    `invalid definition: unexpected character ')', type expected`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseStateVariable(s); return err },
+			input:      "uint256 public x y",
+			wantOffset: 17,
+			wantLine:   1,
+			wantColumn: 18,
+			wantToken:  "y",
+			wantMsg:    `unexpected character 'y' at the end of the variable declaration`,
+		},
+		{
+			parse:      func(s string) error { _, err := ParseInterface(s); return err },
+			input:      "interface I {\n  /* € */ struct P { uint256 a }\n}",
+			wantOffset: 47,
+			wantLine:   2,
+			wantColumn: 32,
+			wantToken:  "}",
+			wantMsg:    `invalid definition: unexpected character '}', ';' expected`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			err := tt.parse(tt.input)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("expected *ParseError, got %T: %v", err, err)
+			}
+			if pe.Input != tt.input {
+				t.Errorf("Input = %q, want %q", pe.Input, tt.input)
+			}
+			if pe.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", pe.Offset, tt.wantOffset)
+			}
+			if pe.Line != tt.wantLine || pe.Column != tt.wantColumn {
+				t.Errorf("Line:Column = %d:%d, want %d:%d", pe.Line, pe.Column, tt.wantLine, tt.wantColumn)
+			}
+			if pe.Token != tt.wantToken {
+				t.Errorf("Token = %q, want %q", pe.Token, tt.wantToken)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
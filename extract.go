@@ -0,0 +1,90 @@
+package sigparser
+
+// ExtractSignatures scans a Solidity source file and returns the function,
+// modifier, event and error signatures declared in it, in the order in which
+// they appear. Unlike ParseContract, it does not require the source to consist
+// of a single declaration and it does not filter out internal and private
+// functions, which makes it useful for auditing arbitrary source files.
+//
+// Function bodies, comments, pragma directives, import statements and
+// declarations other than functions, modifiers, events and errors are
+// ignored.
+// Signatures declared inside a contract, interface or library have their
+// Scope set to its name. Struct references are not resolved, see
+// ResolveStructs.
+//
+// The scanner is tolerant: declarations that cannot be parsed are skipped
+// and scanning continues. In that case, the error for the first of them is
+// returned along with the signatures that were extracted.
+func ExtractSignatures(src string, opts ...Option) ([]Signature, error) {
+	pp := NewParser(opts...)
+	pp.Reset(src)
+	p := &pp.p
+	p.opts.comments = true
+	e := &extractor{}
+	e.extract(p, "")
+	if e.err != nil {
+		return e.sigs, p.parseError(e.err)
+	}
+	return e.sigs, nil
+}
+
+type extractor struct {
+	sigs []Signature
+	err  error
+}
+
+// extract extracts signatures from the declarations in the input. The scope
+// is the name of the enclosing contract, if any.
+func (e *extractor) extract(p *parser, scope string) {
+	for {
+		start, end := p.nextDefinition(true)
+		if start == end {
+			return
+		}
+		def := &parser{in: p.in[:end], pos: start, opts: p.opts}
+		switch kw := string(def.parseName()); kw {
+		case "abstract", "contract", "interface", "library":
+			if kw == "abstract" {
+				def.parseWhitespace()
+				if string(def.parseName()) != "contract" {
+					continue
+				}
+			}
+			name, _, err := def.parseContractHeader()
+			if err != nil {
+				e.fail(def.parseError(err))
+				continue
+			}
+			body, bodyEnd, err := def.parseBody()
+			if err != nil {
+				e.fail(def.parseError(err))
+				continue
+			}
+			e.extract(&parser{in: p.in[:bodyEnd], pos: body, opts: p.opts}, name)
+		case "function", "event", "error", "modifier":
+			// Skip legacy unnamed fallback functions and function type
+			// variables, e.g. "function(uint256) external callback;".
+			def.parseWhitespace()
+			if kw == "function" && def.peekByte('(') {
+				continue
+			}
+			hdr := p.definitionParser(start, end)
+			hdr.p.in = hdr.p.in[:hdr.p.headerEnd()]
+			sig, err := hdr.ParseSignature()
+			if err != nil {
+				e.fail(definitionError(p.in, start, `invalid definition`, err))
+				continue
+			}
+			sig.Scope = scope
+			e.sigs = append(e.sigs, sig)
+		}
+	}
+}
+
+// fail records the error if it is the first one.
+func (e *extractor) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
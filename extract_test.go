@@ -0,0 +1,153 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestExtractSignatures(t *testing.T) {
+	tests := []struct {
+		src     string
+		want    []Signature
+		wantErr string
+	}{
+		{src: ""},
+		{src: "// SPDX-License-Identifier: MIT\npragma solidity ^0.8.0;\nimport \"./A.sol\";\n"},
+		{
+			src: `// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.4;
+
+import {IERC20} from "./IERC20.sol";
+
+error Unauthorized(address caller);
+
+struct Point { uint256 x; uint256 y; }
+
+function distance(Point memory a, Point memory b) pure returns (uint256) {
+    return a.x - b.x; // }
+}
+
+interface IPool {
+    event Swap(address indexed sender, uint256 amount);
+    function swap(uint256 amount) external returns (uint256);
+}
+
+abstract contract Pool is IPool {
+    uint256 internal reserve;
+    function(uint256) external callback;
+
+    modifier onlyRole(bytes32 role) virtual { _; }
+
+    constructor() { reserve = 0; }
+
+    function swap(uint256 amount) external override returns (uint256) {
+        emit Swap(msg.sender, amount);
+        return "{" == "}" ? 0 : amount;
+    }
+
+    function _update(uint256 amount) internal virtual;
+}
+
+library Math {
+    function max(uint256 a, uint256 b) internal pure returns (uint256) {
+        return a > b ? a : b;
+    }
+}
+`,
+			want: []Signature{
+				{Kind: ErrorKind, Name: "Unauthorized", Inputs: []Parameter{{Type: "address", Name: "caller"}}},
+				{
+					Kind:            FunctionKind,
+					Name:            "distance",
+					Inputs:          []Parameter{{Type: "Point", Name: "a", DataLocation: Memory}, {Type: "Point", Name: "b", DataLocation: Memory}},
+					Outputs:         []Parameter{{Type: "uint256"}},
+					Modifiers:       []string{"pure"},
+					ParsedModifiers: []Modifier{{Name: "pure"}},
+					StateMutability: Pure,
+				},
+				{
+					Kind:   EventKind,
+					Name:   "Swap",
+					Scope:  "IPool",
+					Inputs: []Parameter{{Type: "address", Name: "sender", Indexed: true}, {Type: "uint256", Name: "amount"}},
+				},
+				{
+					Kind:            FunctionKind,
+					Name:            "swap",
+					Scope:           "IPool",
+					Inputs:          []Parameter{{Type: "uint256", Name: "amount"}},
+					Outputs:         []Parameter{{Type: "uint256"}},
+					Modifiers:       []string{"external"},
+					ParsedModifiers: []Modifier{{Name: "external"}},
+					Visibility:      External,
+				},
+				{
+					Kind:            ModifierKind,
+					Name:            "onlyRole",
+					Scope:           "Pool",
+					Inputs:          []Parameter{{Type: "bytes32", Name: "role"}},
+					Modifiers:       []string{"virtual"},
+					ParsedModifiers: []Modifier{{Name: "virtual"}},
+				},
+				{
+					Kind:            FunctionKind,
+					Name:            "swap",
+					Scope:           "Pool",
+					Inputs:          []Parameter{{Type: "uint256", Name: "amount"}},
+					Outputs:         []Parameter{{Type: "uint256"}},
+					Modifiers:       []string{"external", "override"},
+					ParsedModifiers: []Modifier{{Name: "external"}, {Name: "override"}},
+					Visibility:      External,
+				},
+				{
+					Kind:            FunctionKind,
+					Name:            "_update",
+					Scope:           "Pool",
+					Inputs:          []Parameter{{Type: "uint256", Name: "amount"}},
+					Modifiers:       []string{"internal", "virtual"},
+					ParsedModifiers: []Modifier{{Name: "internal"}, {Name: "virtual"}},
+					Visibility:      Internal,
+				},
+				{
+					Kind:            FunctionKind,
+					Name:            "max",
+					Scope:           "Math",
+					Inputs:          []Parameter{{Type: "uint256", Name: "a"}, {Type: "uint256", Name: "b"}},
+					Outputs:         []Parameter{{Type: "uint256"}},
+					Modifiers:       []string{"internal", "pure"},
+					ParsedModifiers: []Modifier{{Name: "internal"}, {Name: "pure"}},
+					Visibility:      Internal,
+					StateMutability: Pure,
+				},
+			},
+		},
+		{
+			src: "contract A {\n\tfunction foo(uint256 a b) external {}\n\tfunction bar() external {}\n}\ncontract {}\nerror E();",
+			want: []Signature{
+				{Kind: FunctionKind, Name: "bar", Scope: "A", Modifiers: []string{"external"}, ParsedModifiers: []Modifier{{Name: "external"}}, Visibility: External},
+				{Kind: ErrorKind, Name: "E"},
+			},
+			wantErr: `invalid definition: unexpected character 'b', ',' or ')' expected`,
+		},
+		{
+			src:     "contract A {\n\tfunction foo() external {\n",
+			wantErr: `unclosed '{'`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ExtractSignatures(tt.src)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ExtractSignatures() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("ExtractSignatures() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractSignatures() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
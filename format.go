@@ -0,0 +1,33 @@
+package sigparser
+
+// FormatOption is a formatting option. Format options can be passed to
+// Signature.Format to change how the signature is rendered.
+type FormatOption func(*formatOptions)
+
+type formatOptions struct {
+	flattenSingleReturn bool
+}
+
+// WithFlattenSingleReturn renders a single unnamed tuple output as multiple
+// return values, e.g. "foo() returns (uint256, bool)" instead of
+// "foo() returns ((uint256, bool))", matching how many tools display
+// functions with multiple return values.
+//
+// The flattened form describes a different ABI, so it is meant for display
+// only. The data location of the flattened tuple is dropped. Named tuples
+// and tuple arrays are never flattened.
+func WithFlattenSingleReturn() FormatOption {
+	return func(o *formatOptions) {
+		o.flattenSingleReturn = true
+	}
+}
+
+// isSingleUnnamedTuple returns true if params consists of exactly one unnamed,
+// non-array tuple.
+func isSingleUnnamedTuple(params []Parameter) bool {
+	if len(params) != 1 {
+		return false
+	}
+	p := params[0]
+	return len(p.Type) == 0 && len(p.Tuple) > 0 && len(p.Name) == 0 && len(p.Arrays) == 0
+}
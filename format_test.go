@@ -0,0 +1,33 @@
+package sigparser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSignatureFormat(t *testing.T) {
+	tests := []struct {
+		sig  string
+		opts []FormatOption
+		want string
+	}{
+		{sig: "foo() returns ((uint256, bool))", want: "foo() returns ((uint256, bool))"},
+		{sig: "foo() returns ((uint256 a, bool b))", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns (uint256 a, bool b)"},
+		{sig: "foo() returns ((uint256, bool) memory)", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns (uint256, bool)"},
+		{sig: "foo() returns ((uint256, (bool, bytes)))", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns (uint256, (bool, bytes))"},
+		{sig: "foo() returns (uint256, bool)", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns (uint256, bool)"},
+		{sig: "foo() returns (uint256)", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns (uint256)"},
+		{sig: "foo() returns ((uint256, bool) r)", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns ((uint256, bool) r)"},
+		{sig: "foo() returns ((uint256, bool)[])", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns ((uint256, bool)[])"},
+		{sig: "foo() returns ((uint256), (bool))", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo() returns ((uint256), (bool))"},
+		{sig: "foo((uint256, bool))", opts: []FormatOption{WithFlattenSingleReturn()}, want: "foo((uint256, bool))"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			if got := sig.Format(tt.opts...); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
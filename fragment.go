@@ -0,0 +1,162 @@
+package sigparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FragmentFormat is the format of the fragment returned by FormatFragment.
+// The formats correspond to the formats used by the ethers library.
+type FragmentFormat int8
+
+const (
+	// SighashFormat is the canonical form used to compute selectors, e.g.
+	// "balanceOf(address)".
+	SighashFormat FragmentFormat = iota
+
+	// MinimalFormat is the human-readable form without parameter names, e.g.
+	// "function balanceOf(address) view returns (uint256)".
+	MinimalFormat
+
+	// FullFormat is the human-readable form with parameter names, e.g.
+	// "function balanceOf(address owner) view returns (uint256)".
+	FullFormat
+
+	// JSONFormat is the JSON ABI fragment, as returned by
+	// Signature.MarshalJSON.
+	JSONFormat
+)
+
+// String returns the name of the format, as used by the ethers library.
+func (f FragmentFormat) String() string {
+	switch f {
+	case SighashFormat:
+		return "sighash"
+	case MinimalFormat:
+		return "minimal"
+	case FullFormat:
+		return "full"
+	case JSONFormat:
+		return "json"
+	default:
+		return "unknown"
+	}
+}
+
+// FormatFragment returns the signature as a fragment in the given format,
+// so that it can be consumed by the ethers library.
+//
+// Signatures with UnknownKind are treated as functions. Alias types are
+// expanded to their canonical names, tuples are written using the "tuple"
+// keyword, and data locations are omitted. Of the modifiers, only the state
+// mutability is kept, except for fallback and receive functions, which are
+// always declared "external", as Solidity requires. Anonymous events are
+// marked as such.
+//
+// An error is returned for constructor, fallback and receive signatures in
+// SighashFormat, because they have no selector, for signatures that cannot
+// be represented in JSONFormat and for modifier signatures, which are not
+// a part of the ABI.
+func FormatFragment(sig Signature, format FragmentFormat) (string, error) {
+	if sig.Kind == ModifierKind {
+		return "", fmt.Errorf(`cannot format modifier signature as fragment`)
+	}
+	switch format {
+	case SighashFormat:
+		switch sig.Kind {
+		case ConstructorKind, FallbackKind, ReceiveKind:
+			return "", fmt.Errorf(`cannot format %s signature as sighash`, sig.Kind)
+		}
+		return sig.Canonical(), nil
+	case MinimalFormat, FullFormat:
+		return formatFragment(sig, format == FullFormat), nil
+	case JSONFormat:
+		data, err := json.Marshal(sig)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf(`unknown fragment format %d`, format)
+}
+
+// formatFragment returns the human-readable fragment of sig. If full is
+// true, parameter names are included.
+func formatFragment(sig Signature, full bool) string {
+	var buf strings.Builder
+	mutability := stateMutability(sig)
+	switch sig.Kind {
+	case ConstructorKind:
+		buf.WriteString("constructor")
+		writeFragmentParameters(&buf, sig.Inputs, full)
+		if mutability == "payable" {
+			buf.WriteString(" payable")
+		}
+	case FallbackKind:
+		buf.WriteString("fallback() external")
+		if mutability == "payable" {
+			buf.WriteString(" payable")
+		}
+	case ReceiveKind:
+		buf.WriteString("receive() external payable")
+	case EventKind:
+		buf.WriteString("event ")
+		buf.WriteString(sig.Name)
+		writeFragmentParameters(&buf, sig.Inputs, full)
+		if sig.Anonymous {
+			buf.WriteString(" anonymous")
+		}
+	case ErrorKind:
+		buf.WriteString("error ")
+		buf.WriteString(sig.Name)
+		writeFragmentParameters(&buf, sig.Inputs, full)
+	default:
+		buf.WriteString("function ")
+		buf.WriteString(sig.Name)
+		writeFragmentParameters(&buf, sig.Inputs, full)
+		if mutability != "nonpayable" {
+			buf.WriteByte(' ')
+			buf.WriteString(mutability)
+		}
+		if len(sig.Outputs) > 0 {
+			buf.WriteString(" returns ")
+			writeFragmentParameters(&buf, sig.Outputs, full)
+		}
+	}
+	return buf.String()
+}
+
+// writeFragmentParameters writes params as a parenthesized list. In the full
+// format, the parameters are separated by ", " and include names, otherwise
+// they are separated by ",".
+func writeFragmentParameters(buf *strings.Builder, params []Parameter, full bool) {
+	buf.WriteByte('(')
+	for i, p := range params {
+		if i > 0 {
+			buf.WriteByte(',')
+			if full {
+				buf.WriteByte(' ')
+			}
+		}
+		if len(p.Type) > 0 {
+			typ, err := canonicalElementaryType(p.Type)
+			if err != nil {
+				typ = p.Type
+			}
+			buf.WriteString(typ)
+		} else {
+			buf.WriteString("tuple")
+			writeFragmentParameters(buf, p.Tuple, full)
+		}
+		buf.WriteString(arraySuffix(p.Arrays))
+		if p.Indexed {
+			buf.WriteString(" indexed")
+		}
+		if full && len(p.Name) > 0 {
+			buf.WriteByte(' ')
+			buf.WriteString(p.Name)
+		}
+	}
+	buf.WriteByte(')')
+}
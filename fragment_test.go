@@ -0,0 +1,49 @@
+package sigparser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatFragment(t *testing.T) {
+	tests := []struct {
+		sig     string
+		format  FragmentFormat
+		want    string
+		wantErr bool
+	}{
+		{sig: "function balanceOf(address owner) external view returns (uint)", format: SighashFormat, want: "balanceOf(address)"},
+		{sig: "function balanceOf(address owner) external view returns (uint)", format: MinimalFormat, want: "function balanceOf(address) view returns (uint256)"},
+		{sig: "function balanceOf(address owner) external view returns (uint)", format: FullFormat, want: "function balanceOf(address owner) view returns (uint256)"},
+		{
+			sig:    "function balanceOf(address owner) external view returns (uint)",
+			format: JSONFormat,
+			want:   `{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address","internalType":"address"}],"outputs":[{"name":"","type":"uint256","internalType":"uint256"}],"stateMutability":"view"}`,
+		},
+		{sig: "fill((address maker, uint[] amounts)[2] memory orders) payable", format: MinimalFormat, want: "function fill(tuple(address,uint256[])[2]) payable"},
+		{sig: "fill((address maker, uint[] amounts)[2] memory orders) payable", format: FullFormat, want: "function fill(tuple(address maker, uint256[] amounts)[2] orders) payable"},
+		{sig: "event Transfer(address indexed from, address indexed to, uint value)", format: SighashFormat, want: "Transfer(address,address,uint256)"},
+		{sig: "event Transfer(address indexed from, address indexed to, uint value)", format: MinimalFormat, want: "event Transfer(address indexed,address indexed,uint256)"},
+		{sig: "event Transfer(address indexed from, address indexed to, uint value)", format: FullFormat, want: "event Transfer(address indexed from, address indexed to, uint256 value)"},
+		{sig: "event Log(string msg) anonymous", format: FullFormat, want: "event Log(string msg) anonymous"},
+		{sig: "error Unauthorized(address caller)", format: MinimalFormat, want: "error Unauthorized(address)"},
+		{sig: "constructor(string memory name) payable", format: FullFormat, want: "constructor(string name) payable"},
+		{sig: "fallback() external payable", format: MinimalFormat, want: "fallback() external payable"},
+		{sig: "receive() external payable", format: FullFormat, want: "receive() external payable"},
+		{sig: "constructor(string name)", format: SighashFormat, wantErr: true},
+		{sig: "foo(Bar)", format: JSONFormat, wantErr: true},
+		{sig: "foo()", format: FragmentFormat(42), wantErr: true},
+		{sig: "modifier onlyOwner()", format: MinimalFormat, wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := FormatFragment(mustParseSignature(t, tt.sig), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatFragment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatFragment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package sigparser
+
+import "fmt"
+
+// Interface is a Solidity interface parsed by ParseInterface.
+type Interface struct {
+	Name       string               // Interface name.
+	Bases      []string             // Names of inherited interfaces, if any.
+	Signatures []Signature          // Function, event and error signatures.
+	Structs    map[string]Parameter // Struct definitions keyed by name.
+	Types      map[string]Parameter // User-defined value types keyed by name, if any.
+}
+
+// ParseInterface parses a Solidity interface declaration, e.g.:
+//
+//	interface IERC20 {
+//	    event Transfer(address indexed from, address indexed to, uint256 value);
+//	    function balanceOf(address account) external view returns (uint256);
+//	}
+//
+// The declaration may be preceded by pragma directives and import
+// statements, which are ignored. Comments are allowed anywhere whitespace
+// is allowed. Declarations inside the interface body must be terminated
+// by semicolons, except for struct definitions. Declarations other than
+// functions, events, errors, structs and user-defined value types, such as
+// enums, are skipped. Struct references in the signatures are resolved as
+// described in ResolveStructs, and user-defined value types are replaced
+// with their underlying types.
+//
+// Errors are ParseErrors describing the position in the source at which they
+// occurred.
+func ParseInterface(src string, opts ...Option) (Interface, error) {
+	return parseDeclaration(src, "interface", opts)
+}
+
+// parseDeclaration parses a single interface or contract declaration,
+// depending on the keyword, along with the pragma directives and import
+// statements that precede it. Contracts may be declared abstract.
+func parseDeclaration(src string, keyword string, opts []Option) (Interface, error) {
+	pp := NewParser(opts...)
+	pp.Reset(src)
+	p := &pp.p
+	p.opts.comments = true
+	if err := p.skipDirectives(); err != nil {
+		return Interface{}, p.parseError(err)
+	}
+	if keyword == "contract" && p.peekName() == "abstract" {
+		p.parseName()
+		p.parseWhitespace()
+	}
+	if p.peekName() != keyword {
+		return Interface{}, p.parseError(p.expectedError(fmt.Sprintf(`'%s' keyword`, keyword)))
+	}
+	p.parseName()
+	var (
+		iface Interface
+		err   error
+	)
+	iface.Name, iface.Bases, err = p.parseContractHeader()
+	if err != nil {
+		return Interface{}, p.parseError(err)
+	}
+	body, end, err := p.parseBody()
+	if err != nil {
+		return Interface{}, p.parseError(err)
+	}
+	p.parseWhitespace()
+	if p.hasNext() {
+		return Interface{}, p.parseError(fmt.Errorf(`unexpected input after %s declaration`, keyword))
+	}
+	def := &parser{in: p.in[:end], pos: body, opts: p.opts}
+	iface.Signatures, iface.Structs, iface.Types, err = def.parseDefinitions(true)
+	if err != nil {
+		return Interface{}, p.parseError(err)
+	}
+	return iface, nil
+}
+
+// skipDirectives skips whitespaces, comments, pragma directives and import
+// statements.
+func (p *parser) skipDirectives() error {
+	for {
+		p.parseWhitespace()
+		switch kw := p.peekName(); kw {
+		case "pragma", "import":
+			pos := p.pos
+			for p.hasNext() && p.peek() != ';' {
+				p.read()
+			}
+			if !p.readByte(';') {
+				p.pos = pos
+				return fmt.Errorf(`unterminated %s directive`, kw)
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// parseContractHeader parses the part of a contract, interface or library
+// declaration between the keyword and the opening brace, e.g.
+// "IFoo is IBar, IBaz". It returns the name and the list of base contracts.
+func (p *parser) parseContractHeader() (string, []string, error) {
+	p.parseWhitespace()
+	name := string(p.parseName())
+	if len(name) == 0 {
+		return "", nil, p.expectedError("name")
+	}
+	p.parseWhitespace()
+	if p.peekName() != "is" {
+		return name, nil, nil
+	}
+	p.parseName()
+	var bases []string
+	for {
+		p.parseWhitespace()
+		base := p.parseDottedName()
+		if len(base) == 0 {
+			return "", nil, p.expectedError("base name")
+		}
+		bases = append(bases, string(base))
+		p.parseWhitespace()
+		// Skip base constructor arguments, e.g. "ERC20("Token", "TKN")".
+		if p.peekByte('(') {
+			if err := p.skipBlock('(', ')'); err != nil {
+				return "", nil, err
+			}
+			p.parseWhitespace()
+		}
+		if !p.readByte(',') {
+			break
+		}
+	}
+	return name, bases, nil
+}
+
+// parseBody parses a brace-delimited body and returns the positions right
+// after the opening brace and at the closing brace.
+func (p *parser) parseBody() (start, end int, err error) {
+	p.parseWhitespace()
+	if !p.peekByte('{') {
+		return 0, 0, p.expectedError("'{'")
+	}
+	start = p.pos + 1
+	if err := p.skipBlock('{', '}'); err != nil {
+		return 0, 0, err
+	}
+	return start, p.pos - 1, nil
+}
+
+// skipBlock skips the input from the open byte at the current position up
+// to and including the matching close byte. Comments and string literals
+// are skipped, so the delimiters inside them are ignored.
+func (p *parser) skipBlock(open, close byte) error {
+	pos := p.pos
+	depth := 0
+	for p.hasNext() {
+		if p.parseComment() || p.skipString() {
+			continue
+		}
+		switch p.read() {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	p.pos = pos
+	return fmt.Errorf(`unclosed '%c'`, open)
+}
+
+// expectedError returns an error reporting that the character at the
+// current position, or the end of the input, was found where the given
+// token was expected.
+func (p *parser) expectedError(expected string) error {
+	if !p.hasNext() {
+		return fmt.Errorf(`unexpected end of input, %s expected`, expected)
+	}
+	return fmt.Errorf(`unexpected character %q, %s expected`, p.peek(), expected)
+}
+
+// skipString skips a single or double quoted string literal at the current
+// position, including escaped quotes. It returns false if there is no string
+// literal at the current position. Unterminated literals extend to the end
+// of the input.
+func (p *parser) skipString() bool {
+	if !p.peekByte('"') && !p.peekByte('\'') {
+		return false
+	}
+	quote := p.read()
+	for p.hasNext() {
+		switch p.read() {
+		case '\\':
+			if p.hasNext() {
+				p.read()
+			}
+		case quote:
+			return true
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseInterface(t *testing.T) {
+	pair := []Parameter{{Type: "address", Name: "a"}, {Type: "address", Name: "b"}}
+	tests := []struct {
+		src     string
+		want    Interface
+		wantErr string
+	}{
+		{
+			src:  "interface IEmpty {}",
+			want: Interface{Name: "IEmpty", Structs: map[string]Parameter{}},
+		},
+		{
+			src: `// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IBase.sol";
+
+/// @title ERC-20 token interface
+interface IERC20 is IBase, lib.IOther {
+    /* Emitted on transfers; see {transfer}. */
+    event Transfer(address indexed from, address indexed to, uint256 value);
+
+    error InsufficientBalance(uint256 available, uint256 required);
+
+    function balanceOf(address account)
+        external
+        view
+        returns (uint256); // balance
+}
+`,
+			want: Interface{
+				Name:  "IERC20",
+				Bases: []string{"IBase", "lib.IOther"},
+				Signatures: []Signature{
+					{
+						Kind: EventKind,
+						Name: "Transfer",
+						Inputs: []Parameter{
+							{Type: "address", Name: "from", Indexed: true},
+							{Type: "address", Name: "to", Indexed: true},
+							{Type: "uint256", Name: "value"},
+						},
+					},
+					{
+						Kind:   ErrorKind,
+						Name:   "InsufficientBalance",
+						Inputs: []Parameter{{Type: "uint256", Name: "available"}, {Type: "uint256", Name: "required"}},
+					},
+					{
+						Kind:            FunctionKind,
+						Name:            "balanceOf",
+						Inputs:          []Parameter{{Type: "address", Name: "account"}},
+						Outputs:         []Parameter{{Type: "uint256"}},
+						Modifiers:       []string{"external", "view"},
+						ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+						Visibility:      External,
+						StateMutability: View,
+					},
+				},
+				Structs: map[string]Parameter{},
+			},
+		},
+		{
+			src: "interface ISwap {\n\tenum Side { Buy, Sell }\n\tstruct Pair {\n\t\taddress a;\n\t\taddress b;\n\t}\n\tfunction swap(Pair calldata p) external;\n}",
+			want: Interface{
+				Name: "ISwap",
+				Signatures: []Signature{
+					{
+						Kind:            FunctionKind,
+						Name:            "swap",
+						Inputs:          []Parameter{{Name: "p", Tuple: pair, DataLocation: CallData}},
+						Modifiers:       []string{"external"},
+						ParsedModifiers: []Modifier{{Name: "external"}},
+						Visibility:      External,
+					},
+				},
+				Structs: map[string]Parameter{"Pair": {Name: "Pair", Tuple: pair}},
+			},
+		},
+		{
+			src: "interface IOracle {\n\ttype Price is uint128;\n\tfunction price() external view returns (Price);\n}",
+			want: Interface{
+				Name: "IOracle",
+				Signatures: []Signature{
+					{
+						Kind:            FunctionKind,
+						Name:            "price",
+						Outputs:         []Parameter{{Type: "uint128"}},
+						Modifiers:       []string{"external", "view"},
+						ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+						Visibility:      External,
+						StateMutability: View,
+					},
+				},
+				Structs: map[string]Parameter{},
+				Types:   map[string]Parameter{"Price": {Name: "Price", Type: "uint128"}},
+			},
+		},
+		{
+			src:     "pragma solidity ^0.8.0",
+			wantErr: `unterminated pragma directive`,
+		},
+		{
+			src:     "contract Foo {}",
+			wantErr: `unexpected character 'c', 'interface' keyword expected`,
+		},
+		{
+			src:     "interface {}",
+			wantErr: `unexpected character '{', name expected`,
+		},
+		{
+			src:     "interface IFoo is {}",
+			wantErr: `unexpected character '{', base name expected`,
+		},
+		{
+			src:     "interface IFoo {\n\tfunction foo() external;\n",
+			wantErr: `unclosed '{'`,
+		},
+		{
+			src:     "interface IFoo {}\ninterface IBar {}",
+			wantErr: `unexpected input after interface declaration`,
+		},
+		{
+			src:     "interface IFoo {\n\tfunction foo(uint256 a external;\n}",
+			wantErr: `invalid definition: unexpected character 'e', ',' or ')' expected`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseInterface(tt.src)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseInterface() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInterface() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInterface() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
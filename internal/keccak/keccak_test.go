@@ -0,0 +1,32 @@
+package keccak
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSum256(t *testing.T) {
+	tests := []struct {
+		data []string
+		want string
+	}{
+		{data: nil, want: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{data: []string{"transfer(address,uint256)"}, want: "a9059cbb2ab09eb219583f4a59a5d0623ade346d962bcd4e46b11da047c9049b"},
+		{data: []string{"transfer(", "address,uint256)"}, want: "a9059cbb2ab09eb219583f4a59a5d0623ade346d962bcd4e46b11da047c9049b"},
+		{data: []string{"Transfer(address,address,uint256)"}, want: "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},
+		{data: []string{strings.Repeat("a", 135)}, want: "34367dc248bbd832f4e3e69dfaac2f92638bd0bbd18f2912ba4ef454919cf446"},
+		{data: []string{strings.Repeat("a", 136)}, want: "a6c4d403279fe3e0af03729caada8374b5ca54d8065329a3ebcaeb4b60aa386e"},
+		{data: []string{strings.Repeat("a", 200)}, want: "96ea54061def936c4be90b518992fdc6f12f535068a256229aca54267b4d084d"},
+	}
+	for _, tt := range tests {
+		var data [][]byte
+		for _, d := range tt.data {
+			data = append(data, []byte(d))
+		}
+		got := Sum256(data...)
+		if hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("Sum256(%q) = %x, want %s", tt.data, got, tt.want)
+		}
+	}
+}
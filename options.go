@@ -0,0 +1,214 @@
+package sigparser
+
+// Option is a parser option. Options can be passed to the parsing functions
+// or to NewParser to change the default behavior of the parser.
+type Option func(*options)
+
+type options struct {
+	modifiersAfterReturns  bool
+	preferSignature        bool
+	dottedNames            bool
+	scopedNames            bool
+	requireReturnsKeyword  bool
+	strictEventSyntax      bool
+	lenientArrays          bool
+	strictFallback         bool
+	strictModifiers        bool
+	comments               bool
+	requireTupleFieldNames bool
+	ethersCompat           bool
+	mappingPolicy          MappingPolicy
+	versionProfile         VersionProfile
+}
+
+// MappingPolicy determines how mapping fields in struct definitions are
+// handled, see WithMappingPolicy.
+type MappingPolicy int8
+
+const (
+	// RejectMappings rejects struct definitions with mapping fields.
+	RejectMappings MappingPolicy = iota
+
+	// SkipMappings omits mapping fields from the parsed struct.
+	SkipMappings
+
+	// KeepMappings keeps mapping fields in the parsed struct. Their Type is
+	// "mapping" and the key and value types are stored in the Mapping
+	// field of the parameter.
+	KeepMappings
+)
+
+// VersionProfile selects the Solidity version whose syntax is accepted
+// where it differs between versions, see WithVersionProfile.
+type VersionProfile int8
+
+const (
+	// ModernSolidity accepts the syntax of Solidity 0.7.0 and later.
+	ModernSolidity VersionProfile = iota
+
+	// LegacySolidity accepts the syntax of Solidity versions before 0.7.0,
+	// in which constructors may be declared public or internal.
+	LegacySolidity
+)
+
+// WithModifiersAfterReturns allows modifiers to appear after the return
+// values, e.g. "foo() returns (uint256) view". Such modifiers are appended
+// to the Modifiers list.
+//
+// This is not a valid Solidity syntax, but some tools generate signatures
+// in this form. By default, such signatures are rejected.
+func WithModifiersAfterReturns() Option {
+	return func(o *options) {
+		o.modifiersAfterReturns = true
+	}
+}
+
+// WithPreferSignature makes the Parse function prefer signatures over
+// parameters for ambiguous inputs. For example, "foo" is parsed as
+// a function signature instead of a type.
+func WithPreferSignature() Option {
+	return func(o *options) {
+		o.preferSignature = true
+	}
+}
+
+// WithPreferParameter makes the Parse function prefer parameters over
+// signatures for ambiguous inputs. This is the default behavior.
+func WithPreferParameter() Option {
+	return func(o *options) {
+		o.preferSignature = false
+	}
+}
+
+// WithDottedNames allows dots in function, event and error names, e.g.
+// "foo.v2(uint256)". The dots are only allowed in signature names, not in
+// parameter types or names.
+//
+// The whole dotted name is stored in the Name field and it is a part of
+// the selector. To parse names qualified with a contract name instead, use
+// WithScopedNames. If both options are used, this one takes precedence.
+//
+// This is not a valid Solidity syntax, but some tools use it to add version
+// suffixes to names.
+func WithDottedNames() Option {
+	return func(o *options) {
+		o.dottedNames = true
+	}
+}
+
+// WithScopedNames allows signature names to be qualified with the name of
+// the contract or interface that declares them, e.g.
+// "IERC20.transfer(address,uint256)". The qualifier is stored in the
+// Signature.Scope field and it is not a part of the selector. By default,
+// dots in names are rejected.
+func WithScopedNames() Option {
+	return func(o *options) {
+		o.scopedNames = true
+	}
+}
+
+// WithRequireReturnsKeyword requires return values to be preceded by the
+// "returns" keyword. Signatures using the compact form, such as
+// "foo()(uint256)", are rejected.
+func WithRequireReturnsKeyword() Option {
+	return func(o *options) {
+		o.requireReturnsKeyword = true
+	}
+}
+
+// WithStrictEventSyntax enforces the Solidity grammar for event parameters:
+// the type, followed by an optional "indexed" keyword, followed by an
+// optional name. Any other arrangement, such as "uint256 a indexed", is
+// rejected with a descriptive error.
+func WithStrictEventSyntax() Option {
+	return func(o *options) {
+		o.strictEventSyntax = true
+	}
+}
+
+// WithLenientArrays allows whitespaces before, between and inside array
+// brackets, e.g. "uint256 [ 2 ] [3]". By default, such declarations are
+// rejected.
+func WithLenientArrays() Option {
+	return func(o *options) {
+		o.lenientArrays = true
+	}
+}
+
+// WithStrictFallback requires the data-carrying form of the fallback
+// function to use the same data locations as Solidity, i.e.
+// "fallback(bytes calldata) returns (bytes memory)". By default, any data
+// location is accepted.
+func WithStrictFallback() Option {
+	return func(o *options) {
+		o.strictFallback = true
+	}
+}
+
+// WithStrictModifiers rejects signatures with conflicting modifiers, such as
+// "view pure" or "payable view", modifiers repeated more than once, such as
+// "virtual virtual", and virtual private functions. It also enforces the
+// modifiers required by Solidity for special functions: the receive function
+// must be declared "external payable", and the fallback function must be
+// "external" and must not be view or pure. Multiple visibilities, such as
+// "external private", are rejected as well, in signatures and state variable
+// declarations. By default, any combination of modifiers is accepted, and
+// the first visibility modifier determines the visibility.
+func WithStrictModifiers() Option {
+	return func(o *options) {
+		o.strictModifiers = true
+	}
+}
+
+// WithComments allows Solidity line ("// ...") and block ("/* ... */")
+// comments wherever whitespace is allowed, including after the trailing
+// semicolon, e.g. "transfer(address,uint256); // ERC20 transfer".
+func WithComments() Option {
+	return func(o *options) {
+		o.comments = true
+	}
+}
+
+// WithRequireTupleFieldNames requires every element of a tuple parameter to
+// have a name, as fields of a struct definition do, e.g. "(uint256 a, bool b)".
+// The parameter lists of signatures are not affected, only tuples used as
+// parameters. By default, tuple elements may be unnamed.
+func WithRequireTupleFieldNames() Option {
+	return func(o *options) {
+		o.requireTupleFieldNames = true
+	}
+}
+
+// WithEthersCompat makes the parser accept every valid human-readable ABI
+// fragment used by the ethers library. In particular, it:
+//
+//   - allows events without parameters, e.g. "event Paused()",
+//   - accepts and discards the gas limit suffix, e.g. "function foo() @29000",
+//   - allows whitespaces around array brackets, as WithLenientArrays does.
+//
+// The "tuple" keyword, "address payable" and the trailing semicolon are
+// accepted regardless of this option.
+func WithEthersCompat() Option {
+	return func(o *options) {
+		o.ethersCompat = true
+		o.lenientArrays = true
+	}
+}
+
+// WithMappingPolicy sets how mapping fields in struct definitions, e.g.
+// "mapping(address => uint256) balances", are handled. Such structs cannot
+// be used in signatures, but they are common in Solidity sources. By
+// default, mapping fields are rejected.
+func WithMappingPolicy(policy MappingPolicy) Option {
+	return func(o *options) {
+		o.mappingPolicy = policy
+	}
+}
+
+// WithVersionProfile sets the Solidity version whose syntax is accepted. By
+// default, the ModernSolidity profile is used.
+func WithVersionProfile(profile VersionProfile) Option {
+	return func(o *options) {
+		o.versionProfile = profile
+	}
+}
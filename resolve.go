@@ -0,0 +1,266 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ResolveStructs returns a copy of the signature in which every parameter
+// whose type is the name of one of the given structs is replaced with
+// a tuple containing the struct fields.
+//
+// The structs map is keyed by struct name, and its values are struct
+// definitions as returned by ParseStruct. Struct fields may refer to other
+// structs in the map. Types that are not found in the map are left
+// unchanged. Inline tuples are preserved, but their elements are resolved
+// as well.
+//
+// Recursive structs cannot be represented as tuples. If a struct refers to
+// itself, directly or transitively, an error naming the whole cycle is
+// returned, e.g. `recursive struct "A": A -> B -> A`.
+func ResolveStructs(sig Signature, structs map[string]Parameter) (Signature, error) {
+	return resolveSignature(sig, structs, nil)
+}
+
+// resolveSignature works like ResolveStructs, but it also replaces
+// user-defined value types from the types map with their underlying types.
+func resolveSignature(sig Signature, structs, types map[string]Parameter) (Signature, error) {
+	var err error
+	r := &resolver{structs: structs, types: types}
+	if sig.Inputs, err = r.resolveParams(sig.Inputs); err != nil {
+		return Signature{}, err
+	}
+	if sig.Outputs, err = r.resolveParams(sig.Outputs); err != nil {
+		return Signature{}, err
+	}
+	return sig, nil
+}
+
+// Registry is a collection of struct definitions and user-defined value
+// types used to resolve such types in signatures. The zero value is an
+// empty registry ready to use. A Registry must not be modified concurrently
+// with other operations.
+type Registry struct {
+	structs map[string]Parameter
+	types   map[string]Parameter
+}
+
+// NewRegistry returns a registry containing the given struct definitions.
+// See Register for details.
+func NewRegistry(structs ...Parameter) (*Registry, error) {
+	r := &Registry{}
+	for _, str := range structs {
+		if err := r.Register(str); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a struct definition, as returned by ParseStruct, to the
+// registry. Registering the same definition twice has no effect, but an
+// error is returned if a different struct with the same name is already
+// registered.
+func (r *Registry) Register(str Parameter) error {
+	if len(str.Name) == 0 || len(str.Type) > 0 {
+		return fmt.Errorf(`invalid struct definition %q`, str.Name)
+	}
+	if _, ok := r.types[str.Name]; ok {
+		return fmt.Errorf(`duplicate type %q`, str.Name)
+	}
+	if prev, ok := r.structs[str.Name]; ok {
+		if !reflect.DeepEqual(prev, str) {
+			return fmt.Errorf(`duplicate struct %q`, str.Name)
+		}
+		return nil
+	}
+	if r.structs == nil {
+		r.structs = map[string]Parameter{}
+	}
+	r.structs[str.Name] = str
+	return nil
+}
+
+// RegisterType adds a user-defined value type, as returned by
+// ParseUserDefinedType, to the registry. Registering the same type twice
+// has no effect, but an error is returned if a different type or a struct
+// with the same name is already registered.
+func (r *Registry) RegisterType(typ Parameter) error {
+	if len(typ.Name) == 0 || len(typ.Type) == 0 || len(typ.Tuple) > 0 {
+		return fmt.Errorf(`invalid user-defined value type %q`, typ.Name)
+	}
+	if _, ok := r.structs[typ.Name]; ok {
+		return fmt.Errorf(`duplicate type %q`, typ.Name)
+	}
+	if prev, ok := r.types[typ.Name]; ok {
+		if !reflect.DeepEqual(prev, typ) {
+			return fmt.Errorf(`duplicate type %q`, typ.Name)
+		}
+		return nil
+	}
+	if r.types == nil {
+		r.types = map[string]Parameter{}
+	}
+	r.types[typ.Name] = typ
+	return nil
+}
+
+// Lookup returns the struct definition with the given name.
+func (r *Registry) Lookup(name string) (Parameter, bool) {
+	str, ok := r.structs[name]
+	return str, ok
+}
+
+// Resolve returns a copy of the signature in which struct types are
+// replaced with tuples, as described in ResolveStructs, and user-defined
+// value types are replaced with their underlying types.
+func (r *Registry) Resolve(sig Signature) (Signature, error) {
+	return resolveSignature(sig, r.structs, r.types)
+}
+
+// Validate returns an error if any of the registered structs refers to
+// itself, directly or through other registered structs. The error names
+// the structs that form the cycle, e.g. "A -> B -> A".
+func (r *Registry) Validate() error {
+	names := make([]string, 0, len(r.structs))
+	for name := range r.structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := r.ResolveStruct(r.structs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveStruct returns a copy of the struct definition in which struct
+// types of the fields are replaced with tuples and user-defined value types
+// with their underlying types. An error naming the cycle is returned if the
+// struct refers to itself, directly or through registered structs.
+func (r *Registry) ResolveStruct(str Parameter) (Parameter, error) {
+	return resolveStruct(str, r.structs, r.types)
+}
+
+// ParseSignatureWithRegistry works like ParseSignature, but it also resolves
+// struct types using the given registry, e.g. "foo(Point p)" is parsed as
+// "foo((uint256 x, uint256 y) p)" if Point is registered. Types that are not
+// registered are left unchanged. If the registry is nil, no types are
+// resolved.
+func ParseSignatureWithRegistry(signature string, registry *Registry, opts ...Option) (Signature, error) {
+	sig, err := ParseSignature(signature, opts...)
+	if err != nil {
+		return Signature{}, err
+	}
+	if registry == nil {
+		return sig, nil
+	}
+	return registry.Resolve(sig)
+}
+
+// resolveStruct returns a copy of the struct definition in which struct
+// types of the fields are replaced with tuples and user-defined value types
+// with their underlying types.
+func resolveStruct(str Parameter, structs, types map[string]Parameter) (Parameter, error) {
+	r := &resolver{structs: structs, types: types, path: []string{str.Name}}
+	fields, err := r.resolveParams(str.Tuple)
+	if err != nil {
+		return Parameter{}, err
+	}
+	str.Tuple = fields
+	return str, nil
+}
+
+// resolveTypes returns a copy of the struct definition in which
+// user-defined value types of the fields are replaced with their underlying
+// types. Struct types are left unchanged.
+func resolveTypes(str Parameter, types map[string]Parameter) Parameter {
+	r := &resolver{types: types}
+	str.Tuple, _ = r.resolveParams(str.Tuple) // cannot fail without structs
+	return str
+}
+
+// ParseStructWithRegistry works like ParseStruct, but it also resolves the
+// struct types of the fields using the given registry. Types that are not
+// registered are left unchanged. If the registry is nil, no types are
+// resolved. The parsed struct is not added to the registry.
+func ParseStructWithRegistry(definition string, registry *Registry, opts ...Option) (Parameter, error) {
+	str, err := ParseStruct(definition, opts...)
+	if err != nil {
+		return Parameter{}, err
+	}
+	if registry == nil {
+		return str, nil
+	}
+	return registry.ResolveStruct(str)
+}
+
+type resolver struct {
+	structs map[string]Parameter
+	types   map[string]Parameter
+	path    []string // names of the structs being resolved
+}
+
+func (r *resolver) resolveParams(params []Parameter) ([]Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	resolved := make([]Parameter, len(params))
+	for i, param := range params {
+		var err error
+		if resolved[i], err = r.resolveParam(param); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+func (r *resolver) resolveParam(param Parameter) (Parameter, error) {
+	var err error
+	if len(param.Type) == 0 {
+		param.Tuple, err = r.resolveParams(param.Tuple)
+		return param, err
+	}
+	for i, name := range r.path {
+		if name == param.Type {
+			cycle := append(append([]string{}, r.path[i:]...), name)
+			return Parameter{}, fmt.Errorf(`recursive struct %q: %s`, name, strings.Join(cycle, " -> "))
+		}
+	}
+	if typ, ok := r.types[param.Type]; ok {
+		param.Type = typ.Type
+		param.Payable = typ.Payable
+		param.Arrays = copyArrays(param.Arrays)
+		return param, nil
+	}
+	str, ok := r.structs[param.Type]
+	if !ok {
+		return param, nil
+	}
+	r.path = append(r.path, param.Type)
+	defer func() { r.path = r.path[:len(r.path)-1] }()
+	fields, err := r.resolveParams(str.Tuple)
+	if err != nil {
+		return Parameter{}, err
+	}
+	if fields == nil {
+		fields = []Parameter{}
+	}
+	param.Type = ""
+	param.Tuple = fields
+	param.Arrays = copyArrays(param.Arrays)
+	return param, nil
+}
+
+// copyArrays returns a copy of the array dimensions.
+func copyArrays(arrays []int) []int {
+	if arrays == nil {
+		return nil
+	}
+	cpy := make([]int, len(arrays))
+	copy(cpy, arrays)
+	return cpy
+}
@@ -0,0 +1,248 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestResolveStructs(t *testing.T) {
+	structs := map[string]Parameter{}
+	for _, def := range []string{
+		"struct Point { uint256 x; uint256 y; }",
+		"struct Line { Point a; Point b; }",
+		"struct Empty {}",
+		"struct Node { Node[] children; }",
+	} {
+		str, err := ParseStruct(def)
+		if err != nil {
+			t.Fatal(err)
+		}
+		structs[str.Name] = str
+	}
+	point := []Parameter{{Type: "uint256", Name: "x"}, {Type: "uint256", Name: "y"}}
+	tests := []struct {
+		sig     string
+		want    Signature
+		wantErr bool
+	}{
+		{
+			sig:  "foo(uint256 a)",
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "uint256", Name: "a"}}},
+		},
+		{
+			sig:  "foo(Point a)",
+			want: Signature{Name: "foo", Inputs: []Parameter{{Name: "a", Tuple: point}}},
+		},
+		{
+			sig: "foo(Point a, (uint256 x, uint256 y) b)",
+			want: Signature{Name: "foo", Inputs: []Parameter{
+				{Name: "a", Tuple: point},
+				{Name: "b", Tuple: point},
+			}},
+		},
+		{
+			sig: "foo((Point p, uint256 z)[] a, Point[2] memory b)",
+			want: Signature{Name: "foo", Inputs: []Parameter{
+				{Name: "a", Tuple: []Parameter{{Name: "p", Tuple: point}, {Type: "uint256", Name: "z"}}, Arrays: []int{-1}},
+				{Name: "b", Tuple: point, Arrays: []int{2}, DataLocation: Memory},
+			}},
+		},
+		{
+			sig: "foo() returns (Line l, Empty e)",
+			want: Signature{Name: "foo", Outputs: []Parameter{
+				{Name: "l", Tuple: []Parameter{{Name: "a", Tuple: point}, {Name: "b", Tuple: point}}},
+				{Name: "e", Tuple: []Parameter{}},
+			}},
+		},
+		{
+			sig:  "event foo(Point indexed p)",
+			want: Signature{Kind: EventKind, Name: "foo", Inputs: []Parameter{{Name: "p", Tuple: point, Indexed: true}}},
+		},
+		{
+			sig:  "foo(Unknown a)",
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "Unknown", Name: "a"}}},
+		},
+		{
+			sig:     "foo(Node n)",
+			wantErr: true,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			got, err := ResolveStructs(sig, structs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveStructs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveStructs() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(sig, mustParseSignature(t, tt.sig)) {
+				t.Errorf("ResolveStructs() modified the input signature")
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	point := mustParseStruct(t, "struct Point { uint256 x; uint256 y; }")
+	line := mustParseStruct(t, "struct Line { Point a; Point b; }")
+	r, err := NewRegistry(point, line, point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := r.Lookup("Point"); !ok || !reflect.DeepEqual(got, point) {
+		t.Errorf("Lookup() got = %v, %v, want %v", got, ok, point)
+	}
+	if _, ok := r.Lookup("Unknown"); ok {
+		t.Errorf("Lookup() found unknown struct")
+	}
+	if err := r.Register(mustParseStruct(t, "struct Point { uint256 x; }")); err == nil || err.Error() != `duplicate struct "Point"` {
+		t.Errorf("Register() error = %v", err)
+	}
+	if err := r.Register(Parameter{Type: "uint256"}); err == nil {
+		t.Errorf("Register() expected error")
+	}
+	price, err := ParseUserDefinedType("type Price is uint128")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(price); err == nil {
+		t.Errorf("Register() expected error")
+	}
+	if err := r.RegisterType(point); err == nil {
+		t.Errorf("RegisterType() expected error")
+	}
+	if err := r.RegisterType(price); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterType(Parameter{Name: "Point", Type: "uint256"}); err == nil || err.Error() != `duplicate type "Point"` {
+		t.Errorf("RegisterType() error = %v", err)
+	}
+	var zero Registry
+	if err := zero.Register(point); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		sig      string
+		registry *Registry
+		want     string
+		wantErr  bool
+	}{
+		{sig: "foo(Point p)", registry: r, want: "foo((uint256 x, uint256 y) p)"},
+		{sig: "foo(Line[] memory l)", registry: r, want: "foo(((uint256 x, uint256 y) a, (uint256 x, uint256 y) b)[] memory l)"},
+		{sig: "foo(Point p, Unknown u)", registry: &zero, want: "foo((uint256 x, uint256 y) p, Unknown u)"},
+		{sig: "foo(Point p)", want: "foo(Point p)"},
+		{sig: "foo(Price[2] memory p) returns (Price)", registry: r, want: "foo(uint128[2] memory p) returns (uint128)"},
+		{sig: "foo(Point p", registry: r, wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseSignatureWithRegistry(tt.sig, tt.registry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSignatureWithRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseSignatureWithRegistry() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStructWithRegistry(t *testing.T) {
+	r, err := NewRegistry(
+		mustParseStruct(t, "struct Point { uint256 x; uint256 y; }"),
+		mustParseStruct(t, "struct Loop { Loop next; }"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point := []Parameter{{Type: "uint256", Name: "x"}, {Type: "uint256", Name: "y"}}
+	tests := []struct {
+		def      string
+		registry *Registry
+		want     Parameter
+		wantErr  bool
+	}{
+		{
+			def:      "struct Line { Point a; Point[2] b; Unknown c; }",
+			registry: r,
+			want: Parameter{Name: "Line", Tuple: []Parameter{
+				{Name: "a", Tuple: point},
+				{Name: "b", Tuple: point, Arrays: []int{2}},
+				{Name: "c", Type: "Unknown"},
+			}},
+		},
+		{
+			def:      "struct Shape { (Point center, uint256 radius) circle; }",
+			registry: r,
+			want: Parameter{Name: "Shape", Tuple: []Parameter{
+				{Name: "circle", Tuple: []Parameter{{Name: "center", Tuple: point}, {Name: "radius", Type: "uint256"}}},
+			}},
+		},
+		{
+			def:  "struct Line { Point a; }",
+			want: Parameter{Name: "Line", Tuple: []Parameter{{Name: "a", Type: "Point"}}},
+		},
+		{def: "struct Line { Loop a; }", registry: r, wantErr: true},
+		{def: "struct Point { Point a; }", registry: &Registry{}, wantErr: true},
+		{def: "struct Line { Point a }", registry: r, wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseStructWithRegistry(tt.def, tt.registry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStructWithRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStructWithRegistry() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	tests := []struct {
+		defs    []string
+		wantErr string
+	}{
+		{defs: nil},
+		{defs: []string{"struct A { B b; }", "struct B { uint256 x; }"}},
+		{
+			defs:    []string{"struct Node { Node[] children; }"},
+			wantErr: `recursive struct "Node": Node -> Node`,
+		},
+		{
+			defs:    []string{"struct A { B[] b; }", "struct B { (uint256 x, C c) t; }", "struct C { A a; }"},
+			wantErr: `recursive struct "A": A -> B -> C -> A`,
+		},
+		{
+			defs:    []string{"struct A { B b; }", "struct B { C c; }", "struct C { B b; }"},
+			wantErr: `recursive struct "B": B -> C -> B`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			r := &Registry{}
+			for _, def := range tt.defs {
+				if err := r.Register(mustParseStruct(t, def)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := r.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
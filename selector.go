@@ -0,0 +1,33 @@
+package sigparser
+
+import "github.com/defiweb/go-sigparser/internal/keccak"
+
+// HashFunc is a function that returns the 32-byte hash of the concatenated
+// data, such as Keccak-256.
+type HashFunc func(data ...[]byte) [32]byte
+
+// Selector returns the 4-byte selector of the signature, computed using
+// the built-in Keccak-256 implementation, so the package does not depend on
+// any third-party cryptographic library. Use SelectorOf to compute the
+// selector with a different hash function. See SelectorOf for details.
+func (s Signature) Selector() [4]byte {
+	return SelectorOf(s, keccak.Sum256)
+}
+
+// SelectorOf returns the 4-byte selector of the signature, computed as the
+// first four bytes of the hash of its canonical form. The hash function must
+// be Keccak-256 for the result to match the selectors used by Ethereum.
+//
+// For events, the selector is the first four bytes of the event topic.
+// Constructor, fallback, receive and modifier signatures have no selector,
+// so the zero value is returned for them.
+func SelectorOf(sig Signature, hash HashFunc) [4]byte {
+	var sel [4]byte
+	switch sig.Kind {
+	case ConstructorKind, FallbackKind, ReceiveKind, ModifierKind:
+		return sel
+	}
+	h := hash([]byte(sig.Canonical()))
+	copy(sel[:], h[:4])
+	return sel
+}
@@ -0,0 +1,50 @@
+package sigparser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func TestSignatureSelector(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want string
+	}{
+		{sig: "transfer(address,uint256)", want: "a9059cbb"},
+		{sig: "function transfer(address to, uint amount) external returns (bool)", want: "a9059cbb"},
+		{sig: "balanceOf(address)", want: "70a08231"},
+		{sig: "error Error(string)", want: "08c379a0"},
+		{sig: "event Transfer(address indexed from, address indexed to, uint256 value)", want: "ddf252ad"},
+		{sig: "constructor(uint256)", want: "00000000"},
+		{sig: "fallback()", want: "00000000"},
+		{sig: "receive() external payable", want: "00000000"},
+		{sig: "modifier onlyOwner()", want: "00000000"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sel := mustParseSignature(t, tt.sig).Selector()
+			if got := hex.EncodeToString(sel[:]); got != tt.want {
+				t.Errorf("Signature.Selector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorOf(t *testing.T) {
+	var hashed []byte
+	hash := func(data ...[]byte) (h [32]byte) {
+		for _, d := range data {
+			hashed = append(hashed, d...)
+		}
+		copy(h[:], "abcd")
+		return h
+	}
+	sig := mustParseSignature(t, "function transfer(address to, uint amount)")
+	if got := SelectorOf(sig, hash); got != [4]byte{'a', 'b', 'c', 'd'} {
+		t.Errorf("SelectorOf() = %x, want %x", got, "abcd")
+	}
+	if string(hashed) != "transfer(address,uint256)" {
+		t.Errorf("SelectorOf() hashed %q, want %q", hashed, "transfer(address,uint256)")
+	}
+}
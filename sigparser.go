@@ -7,10 +7,10 @@ import (
 	"strings"
 )
 
-// ParseSignature parses the function, constructor, fallback, receive, event or
-// error signature. The syntax is similar to that of Solidity, but it is less
-// strict. The argument names are always optional, and the return keyword can
-// be omitted.
+// ParseSignature parses the function, constructor, fallback, receive, event,
+// error or modifier signature. The syntax is similar to that of Solidity,
+// but it is less strict. The argument names are always optional, and the
+// return keyword can be omitted.
 //
 // Tuples are represented as a list of types enclosed in parentheses, optionally
 // prefixed with the "tuple" keyword.
@@ -24,6 +24,11 @@ import (
 //   - receive
 //   - event
 //   - error
+//   - modifier
+//
+// Modifier signatures, e.g. "modifier onlyRole(bytes32 role)", describe
+// Solidity function modifiers. They are not a part of the ABI and have no
+// selector, and the parameter list may be omitted, as in Solidity.
 //
 // The following examples are valid signatures:
 //
@@ -36,10 +41,28 @@ import (
 //   - event Foo(uint256 a, uint256 b)
 //   - error Foo(uint256 a, uint256 b)
 //
+// With the WithScopedNames option, the name may be qualified with the name
+// of the contract or interface that declares it, e.g.
+// "IERC20.transfer(address to, uint256 amount)". The qualifier is stored in
+// the Scope field and does not affect the selector.
+//
+// Modifier invocations may have arguments, e.g. "onlyRole(ADMIN)". Because
+// the "returns" keyword is optional, the argument list must immediately
+// follow the modifier name. A list separated from the name by a whitespace
+// is parsed as the return values, and so is a list that starts with an
+// elementary type, e.g. "nonReentrant(uint256)". The arguments are stored
+// in the ParsedModifiers field.
+//
+// Parameters may be function types, e.g. "function(uint256) external
+// returns (bool) callback". Unlike in signatures, the "returns" keyword is
+// required in function types. See the Parameter.Function field.
+//
 // Signatures that are syntactically correct, but semantically invalid are
 // rejected by the parser.
-func ParseSignature(signature string) (Signature, error) {
-	return ParseSignatureAs(UnknownKind, signature)
+//
+// The parser behavior can be adjusted using options.
+func ParseSignature(signature string, opts ...Option) (Signature, error) {
+	return ParseSignatureAs(UnknownKind, signature, opts...)
 }
 
 // ParseSignatureAs works like ParseSignature, but it allows to specify the
@@ -47,55 +70,238 @@ func ParseSignature(signature string) (Signature, error) {
 //
 // The kind can be UnknownKind, in which case the kind is inferred from the
 // signature.
-func ParseSignatureAs(kind SignatureKind, signature string) (Signature, error) {
-	p := &parser{in: []byte(signature)}
-	p.parseWhitespace()
-	sig, err := p.parseSignature(kind)
-	if err != nil {
-		return Signature{}, err
-	}
-	if !p.onlyWhitespaceOrDelimiterLeft() {
-		return Signature{}, fmt.Errorf(`unexpected character %q at the end of the signature`, p.peek())
-	}
-	return sig, nil
+//
+// When the kind is specified, the parameter list may be omitted, because
+// there is no ambiguity between a signature and a type. For example, "foo"
+// parsed as FunctionKind is a function named "foo" with nil Inputs. Likewise,
+// both "" and "fallback" are valid fallback signatures, and both "" and
+// "receive" are valid receive signatures. Because fallback and receive
+// functions have no name, a modifier keyword following the kind keyword is
+// parsed as a modifier, e.g. "fallback external". Any other word, as in
+// "fallback foo", is rejected as a name.
+func ParseSignatureAs(kind SignatureKind, signature string, opts ...Option) (Signature, error) {
+	p := NewParser(opts...)
+	p.Reset(signature)
+	return p.ParseSignatureAs(kind)
 }
 
 // ParseParameter parses the single parameter. The syntax is same as for
 // parameters in the ParseSignature function.
-func ParseParameter(signature string) (Parameter, error) {
-	p := &parser{in: []byte(signature)}
+func ParseParameter(signature string, opts ...Option) (Parameter, error) {
+	p := NewParser(opts...)
+	p.Reset(signature)
+	return p.ParseParameter()
+}
+
+// ParseArrayDims parses array dimensions, e.g. "[2][]", and returns them in
+// the same form as the Parameter.Arrays field, i.e. []int{2, -1}. An empty
+// input has no dimensions.
+//
+// The dimensions are validated the same way as in ParseParameter.
+func ParseArrayDims(s string, opts ...Option) ([]int, error) {
+	pp := NewParser(opts...)
+	pp.Reset(s)
+	p := &pp.p
 	p.parseWhitespace()
-	typ, err := p.parseParameter()
+	arr, err := p.parseArray()
 	if err != nil {
-		return Parameter{}, err
+		return nil, p.parseError(err)
 	}
 	if !p.onlyWhitespaceOrDelimiterLeft() {
-		return Parameter{}, fmt.Errorf(`unexpected character %q at the end of the parameter`, p.peek())
+		return nil, p.parseError(fmt.Errorf(`unexpected character %q at the end of the array dimensions`, p.peek()))
 	}
-	return typ, nil
+	return arr, nil
 }
 
 // ParseStruct parses the struct definition.
 //
 // It returns a structure as a tuple type where the tuple name is the struct
-// name and the tuple elements are the struct fields.
-func ParseStruct(definition string) (Parameter, error) {
-	p := &parser{in: []byte(definition)}
-	p.parseWhitespace()
-	str, err := p.parseStruct()
+// name and the tuple elements are the struct fields. Field types may be
+// elementary types, names of other structs or inline tuples, e.g.
+// "struct Order { Asset asset; (address, uint256)[] fees; }". Elements of
+// inline tuples may be named, but they cannot have data locations or
+// the indexed flag. Struct names are not resolved, see
+// ParseStructWithRegistry.
+//
+// Mapping fields are rejected by default, because mappings are not valid
+// ABI types. This can be changed using the WithMappingPolicy option.
+func ParseStruct(definition string, opts ...Option) (Parameter, error) {
+	p := NewParser(opts...)
+	p.Reset(definition)
+	return p.ParseStruct()
+}
+
+// ParseUserDefinedType parses a user-defined value type declaration, e.g.
+// "type Price is uint256".
+//
+// It returns a parameter whose Name is the name of the declared type and
+// Type is the underlying type, which must be an elementary value type.
+// Such parameters can be registered in a Registry using RegisterType, to
+// replace the declared type with the underlying type, as the ABI does.
+func ParseUserDefinedType(definition string, opts ...Option) (Parameter, error) {
+	p := NewParser(opts...)
+	p.Reset(definition)
+	return p.ParseUserDefinedType()
+}
+
+// Parse parses the input as a parameter, a signature or a struct definition,
+// whichever matches first, and returns the parsed value. The returned value is
+// either a Signature or a Parameter. Struct definitions are returned as
+// a Parameter, in the same form as returned by ParseStruct.
+//
+// Some inputs, such as "foo", are valid both as a parameter and as
+// a signature. Like the Kind function, Parse prefers parameters by default.
+// This can be changed using the WithPreferSignature option.
+//
+// If the input cannot be parsed, the error from the attempt that got
+// the furthest into the input is returned.
+func Parse(input string, opts ...Option) (interface{}, error) {
+	p := NewParser(opts...)
+	p.Reset(input)
+	var (
+		err    error
+		errPos = -1
+	)
+	try := func(fn func() (interface{}, error)) (interface{}, bool) {
+		v, e := fn()
+		if e == nil {
+			return v, true
+		}
+		if p.p.pos > errPos {
+			err, errPos = e, p.p.pos
+		}
+		return nil, false
+	}
+	parseParameter := func() (interface{}, error) { return p.ParseParameter() }
+	parseSignature := func() (interface{}, error) { return p.ParseSignature() }
+	parseStruct := func() (interface{}, error) { return p.ParseStruct() }
+	order := []func() (interface{}, error){parseParameter, parseSignature, parseStruct}
+	if p.p.opts.preferSignature {
+		order = []func() (interface{}, error){parseSignature, parseParameter, parseStruct}
+	}
+	for _, fn := range order {
+		if v, ok := try(fn); ok {
+			return v, nil
+		}
+	}
+	return nil, err
+}
+
+// Parser is a reusable parser for signatures, parameters and structs.
+//
+// Unlike the package-level functions, which allocate a new parser for every
+// call, a Parser can be pointed at a new input using the Reset method. The
+// internal buffer is reused between inputs, which reduces allocations when
+// parsing a large number of signatures.
+//
+// The zero value is ready to use. A Parser must not be used concurrently
+// from multiple goroutines.
+type Parser struct {
+	p parser
+}
+
+// NewParser returns a new Parser configured with the given options.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{}
+	for _, opt := range opts {
+		opt(&p.p.opts)
+	}
+	return p
+}
+
+// Reset sets the input to be parsed. The internal buffer is reused if it is
+// large enough to hold the input.
+func (p *Parser) Reset(input string) {
+	p.p.in = append(p.p.in[:0], input...)
+	p.p.pos = 0
+}
+
+// definitionParser returns a Parser for the part of the input between start
+// and end, configured with the same options as p. The input is shared, so
+// the returned Parser must not be reset.
+func (p *parser) definitionParser(start, end int) *Parser {
+	return &Parser{p: parser{in: p.in[start:end], opts: p.opts}}
+}
+
+// ParseSignature parses the input as a signature. See the ParseSignature
+// function for the supported syntax.
+func (p *Parser) ParseSignature() (Signature, error) {
+	return p.ParseSignatureAs(UnknownKind)
+}
+
+// ParseSignatureAs parses the input as a signature of the given kind. See the
+// ParseSignatureAs function for details.
+func (p *Parser) ParseSignatureAs(kind SignatureKind) (Signature, error) {
+	p.p.pos = 0
+	p.p.parseWhitespace()
+	sig, err := p.p.parseSignature(kind)
 	if err != nil {
-		return Parameter{}, err
+		return Signature{}, p.p.parseError(err)
 	}
-	if !p.onlyWhitespaceOrDelimiterLeft() {
-		return Parameter{}, fmt.Errorf(`unexpected character %q at the end of the struct`, p.peek())
+	if !p.p.onlyWhitespaceOrDelimiterLeft() {
+		return Signature{}, p.p.parseError(fmt.Errorf(`unexpected character %q at the end of the signature`, p.p.peek()))
+	}
+	return sig, nil
+}
+
+// ParseParameter parses the input as a single parameter. See the
+// ParseParameter function for details.
+func (p *Parser) ParseParameter() (Parameter, error) {
+	p.p.pos = 0
+	p.p.parseWhitespace()
+	typ, err := p.p.parseParameter()
+	if err != nil {
+		return Parameter{}, p.p.parseError(err)
+	}
+	if !p.p.onlyWhitespaceOrDelimiterLeft() {
+		return Parameter{}, p.p.parseError(fmt.Errorf(`unexpected character %q at the end of the parameter`, p.p.peek()))
+	}
+	return typ, nil
+}
+
+// ParseStruct parses the input as a struct definition. See the ParseStruct
+// function for details.
+func (p *Parser) ParseStruct() (Parameter, error) {
+	p.p.pos = 0
+	p.p.parseWhitespace()
+	str, err := p.p.parseStruct()
+	if err != nil {
+		return Parameter{}, p.p.parseError(err)
+	}
+	if !p.p.onlyWhitespaceOrDelimiterLeft() {
+		return Parameter{}, p.p.parseError(fmt.Errorf(`unexpected character %q at the end of the struct`, p.p.peek()))
 	}
 	return str, nil
 }
 
+// ParseUserDefinedType parses the input as a user-defined value type
+// declaration. See the ParseUserDefinedType function for details.
+func (p *Parser) ParseUserDefinedType() (Parameter, error) {
+	p.p.pos = 0
+	p.p.parseWhitespace()
+	typ, err := p.p.parseUserDefinedType()
+	if err != nil {
+		return Parameter{}, p.p.parseError(err)
+	}
+	if !p.p.onlyWhitespaceOrDelimiterLeft() {
+		return Parameter{}, p.p.parseError(fmt.Errorf(`unexpected character %q at the end of the type declaration`, p.p.peek()))
+	}
+	return typ, nil
+}
+
 // Kind returns the kind of the input string.
 //
 // This function helps determine which parser should be used to parse the
-// input.
+// input. Inputs starting with '[' or '{' are reported as JSONABIInput
+// without further validation.
+//
+// Inputs that are not a single type are checked whether they are a list of
+// signatures before they are checked whether they are a single signature,
+// so "foo()\nbar()" is a list of two functions, not the function "foo" with
+// the modifier "bar()". Inputs that are not a single signature or struct
+// definition are checked whether they are an interface, a contract or
+// a list of definitions, in that order. Lists must contain at least two
+// elements.
 //
 // Note that some inputs are ambiguous. They could be interpreted either
 // as a type or a function signature. For example, "foo" could be a type or a
@@ -105,40 +311,129 @@ func ParseStruct(definition string) (Parameter, error) {
 // To avoid ambiguity, always add an empty parameter list to function
 // signatures.
 func Kind(input string) (k InputKind) {
+	if kinds := inputKinds(input, false); len(kinds) > 0 {
+		return kinds[0]
+	}
+	return InvalidInput
+}
+
+// KindCandidates returns all kinds the input string can be interpreted as,
+// in the order of priority used by the Kind function. For example, for
+// "foo", which may be a type or a function signature, it returns TypeInput
+// and FunctionSignatureInput. It returns nil for invalid inputs.
+//
+// This function is useful for interactive tools, which may ask the user
+// to choose the interpretation instead of relying on the first one.
+func KindCandidates(input string) []InputKind {
+	return inputKinds(input, true)
+}
+
+// inputKinds returns the kinds of the input in the order of priority. If
+// all is false, it stops after the first match.
+func inputKinds(input string, all bool) []InputKind {
+	var kinds []InputKind
+	add := func(kind InputKind) bool {
+		kinds = append(kinds, kind)
+		return !all
+	}
 	p := &parser{in: []byte(input)}
 	p.parseWhitespace()
 	pos := p.pos
+	if p.peekByte('[') || p.peekByte('{') {
+		return []InputKind{JSONABIInput}
+	}
 	if param, err := p.parseParameter(); err == nil && p.onlyWhitespaceOrDelimiterLeft() {
-		if len(param.Arrays) > 0 {
-			return ArrayInput
+		kind := TypeInput
+		switch {
+		case len(param.Arrays) > 0:
+			kind = ArrayInput
+		case len(param.Tuple) > 0:
+			kind = TupleInput
 		}
-		if len(param.Tuple) > 0 {
-			return TupleInput
+		if add(kind) {
+			return kinds
+		}
+	}
+	// Lists are checked before single signatures, because a new line is
+	// a whitespace in a signature, so "foo()\nbar()" would be parsed as
+	// the function "foo" with the modifier "bar()". Signatures that wrap
+	// onto the next line, e.g. "foo()\nreturns (uint256)", are a single
+	// list entry, so they are still reported as signatures.
+	if sigs, err := ParseSignatures(input); err == nil && len(sigs) > 1 {
+		if add(SignatureListInput) {
+			return kinds
 		}
-		return TypeInput
 	}
 	p.pos = pos
 	if sig, err := p.parseSignature(UnknownKind); err == nil && p.onlyWhitespaceOrDelimiterLeft() {
+		kind := FunctionSignatureInput
 		switch sig.Kind {
-		case FunctionKind, UnknownKind:
-			return FunctionSignatureInput
 		case ConstructorKind:
-			return ConstructorSignatureInput
+			kind = ConstructorSignatureInput
 		case FallbackKind:
-			return FallbackSignatureInput
+			kind = FallbackSignatureInput
 		case ReceiveKind:
-			return ReceiveSignatureInput
+			kind = ReceiveSignatureInput
 		case EventKind:
-			return EventSignatureInput
+			kind = EventSignatureInput
 		case ErrorKind:
-			return ErrorSignatureInput
+			kind = ErrorSignatureInput
+		case ModifierKind:
+			kind = ModifierSignatureInput
+		}
+		if add(kind) {
+			return kinds
 		}
 	}
 	p.pos = pos
 	if _, err := p.parseStruct(); err == nil && p.onlyWhitespaceOrDelimiterLeft() {
-		return StructDefinitionInput
+		if add(StructDefinitionInput) {
+			return kinds
+		}
 	}
-	return InvalidInput
+	if _, err := ParseInterface(input); err == nil {
+		if add(InterfaceInput) {
+			return kinds
+		}
+	}
+	if _, err := ParseContract(input); err == nil {
+		if add(ContractInput) {
+			return kinds
+		}
+	}
+	if sigs, structs, err := ParseABIDefinitions(input); err == nil && len(sigs)+len(structs) > 1 {
+		add(DefinitionListInput)
+	}
+	return kinds
+}
+
+// StripKind detects the kind keyword at the beginning of the input, such as
+// "function" or "event", and returns the kind and the input with the keyword
+// and the whitespaces surrounding it removed. For example, for the input
+// "event Transfer(address)", it returns EventKind and "Transfer(address)".
+//
+// If the input does not start with a kind keyword, or if the keyword may be
+// a part of a type or a name, UnknownKind and the original input are
+// returned. This is the case for inputs like "functions()" or
+// "function foo", where "function" is a type, or "error", which may be
+// a name of a struct.
+func StripKind(input string) (SignatureKind, string) {
+	p := &parser{in: []byte(input)}
+	p.parseWhitespace()
+	pos := p.pos
+	if _, err := p.parseParameter(); err == nil && p.onlyWhitespaceOrDelimiterLeft() {
+		return UnknownKind, input
+	}
+	p.pos = pos
+	kind := p.parseSignatureKind()
+	if kind == UnknownKind {
+		return UnknownKind, input
+	}
+	if p.hasNext() && (isAlpha(p.peek()) || isDigit(p.peek()) || isIdentifierSymbol(p.peek()) || p.peekByte('[')) {
+		return UnknownKind, input
+	}
+	p.parseWhitespace()
+	return kind, input[p.pos:]
 }
 
 // InputKind is the kind of the input string returned by the Kind function.
@@ -156,6 +451,22 @@ const (
 	ReceiveSignatureInput
 	EventSignatureInput
 	ErrorSignatureInput
+	JSONABIInput
+	ModifierSignatureInput
+
+	// InterfaceInput is an interface declaration, see ParseInterface.
+	InterfaceInput
+
+	// ContractInput is a contract declaration, see ParseContract.
+	ContractInput
+
+	// SignatureListInput is a list of signatures separated by semicolons or
+	// new lines, see ParseSignatures.
+	SignatureListInput
+
+	// DefinitionListInput is a list of signatures, struct definitions and
+	// user-defined value types, see ParseABIDefinitions.
+	DefinitionListInput
 )
 
 func (k InputKind) String() string {
@@ -182,6 +493,18 @@ func (k InputKind) String() string {
 		return "event"
 	case ErrorSignatureInput:
 		return "error"
+	case JSONABIInput:
+		return "json"
+	case ModifierSignatureInput:
+		return "modifier"
+	case InterfaceInput:
+		return "interface"
+	case ContractInput:
+		return "contract"
+	case SignatureListInput:
+		return "signatures"
+	case DefinitionListInput:
+		return "definitions"
 	default:
 		return "unknown"
 	}
@@ -192,7 +515,7 @@ func (k InputKind) String() string {
 // It can be parsed using ParseSignature function.
 func (k InputKind) IsSignature() bool {
 	switch k {
-	case FunctionSignatureInput, ConstructorSignatureInput, FallbackSignatureInput, ReceiveSignatureInput, EventSignatureInput, ErrorSignatureInput:
+	case FunctionSignatureInput, ConstructorSignatureInput, FallbackSignatureInput, ReceiveSignatureInput, EventSignatureInput, ErrorSignatureInput, ModifierSignatureInput:
 		return true
 	default:
 		return false
@@ -211,6 +534,23 @@ func (k InputKind) IsParameter() bool {
 	}
 }
 
+// IsJSON returns true if the input looks like a JSON ABI, either an array
+// of fragments or a single fragment object.
+func (k InputKind) IsJSON() bool {
+	return k == JSONABIInput
+}
+
+// IsSource returns true if the input is an interface or a contract
+// declaration.
+func (k InputKind) IsSource() bool {
+	return k == InterfaceInput || k == ContractInput
+}
+
+// IsList returns true if the input is a list of signatures or definitions.
+func (k InputKind) IsList() bool {
+	return k == SignatureListInput || k == DefinitionListInput
+}
+
 // IsStruct returns true if the input is a struct definition.
 //
 // It can be parsed using ParseStruct function.
@@ -230,6 +570,7 @@ const (
 	ReceiveKind
 	EventKind
 	ErrorKind
+	ModifierKind
 )
 
 func (s SignatureKind) String() string {
@@ -246,6 +587,8 @@ func (s SignatureKind) String() string {
 		return "event"
 	case ErrorKind:
 		return "error"
+	case ModifierKind:
+		return "modifier"
 	default:
 		return "unknown"
 	}
@@ -275,6 +618,163 @@ func (d DataLocation) String() string {
 	}
 }
 
+// StateMutability is the state mutability of a function, as used in the
+// JSON ABI.
+type StateMutability int8
+
+const (
+	// UnspecifiedMutability means that the mutability is not declared. In
+	// the ABI, it is treated as NonPayable for functions, constructors
+	// and fallback functions.
+	UnspecifiedMutability StateMutability = iota
+	NonPayable
+	Payable
+	View
+	Pure
+)
+
+func (m StateMutability) String() string {
+	switch m {
+	case NonPayable:
+		return "nonpayable"
+	case Payable:
+		return "payable"
+	case View:
+		return "view"
+	case Pure:
+		return "pure"
+	default:
+		return ""
+	}
+}
+
+// findStateMutability returns the state mutability declared by the given
+// modifiers. The legacy "constant" modifier is reported as View. The second
+// return value is false if none of the modifiers declares a mutability.
+func findStateMutability(modifiers []string) (StateMutability, bool) {
+	for _, mod := range modifiers {
+		switch mod {
+		case "nonpayable":
+			return NonPayable, true
+		case "payable":
+			return Payable, true
+		case "view", "constant":
+			return View, true
+		case "pure":
+			return Pure, true
+		}
+	}
+	return UnspecifiedMutability, false
+}
+
+// Visibility is the visibility of a function.
+type Visibility int8
+
+const (
+	// UnspecifiedVisibility means that no visibility modifier is declared.
+	UnspecifiedVisibility Visibility = iota
+
+	// External functions are part of the contract interface and can only be
+	// called from other contracts and transactions.
+	External
+
+	// Public functions are part of the contract interface and can also be
+	// called internally.
+	Public
+
+	// Internal functions can only be called from the contract and the
+	// contracts deriving from it. They are not part of the ABI.
+	Internal
+
+	// Private functions can only be called from the contract that defines
+	// them. They are not part of the ABI.
+	Private
+)
+
+func (v Visibility) String() string {
+	switch v {
+	case External:
+		return "external"
+	case Public:
+		return "public"
+	case Internal:
+		return "internal"
+	case Private:
+		return "private"
+	default:
+		return ""
+	}
+}
+
+// findVisibility returns the visibility declared by the given modifiers.
+// If more than one visibility is declared, an error is returned in strict
+// mode, and the first one is returned otherwise.
+func findVisibility(modifiers []string, strict bool) (Visibility, error) {
+	var vis Visibility
+	for _, mod := range modifiers {
+		var v Visibility
+		switch mod {
+		case "external":
+			v = External
+		case "public":
+			v = Public
+		case "internal":
+			v = Internal
+		case "private":
+			v = Private
+		default:
+			continue
+		}
+		if vis == UnspecifiedVisibility {
+			vis = v
+		} else if strict {
+			return UnspecifiedVisibility, fmt.Errorf(`multiple visibility modifiers: %q and %q`, vis.String(), mod)
+		}
+	}
+	return vis, nil
+}
+
+// validateModifiers checks that the signature modifiers do not conflict
+// with each other. It is only used with the WithStrictModifiers option.
+func validateModifiers(sig Signature) error {
+	var mutability string
+	seen := map[string]bool{}
+	for _, mod := range sig.Modifiers {
+		if !isModifierKeyword(mod) && mod != "override" {
+			// Custom modifiers may be invoked more than once.
+			continue
+		}
+		if seen[mod] {
+			return fmt.Errorf(`duplicate modifier %q`, mod)
+		}
+		seen[mod] = true
+		switch mod {
+		case "payable", "nonpayable", "view", "pure", "constant":
+			if len(mutability) > 0 {
+				return fmt.Errorf(`conflicting state mutability modifiers %q and %q`, mutability, mod)
+			}
+			mutability = mod
+		}
+	}
+	if sig.Visibility == Private && seen["virtual"] {
+		return fmt.Errorf(`modifier "virtual" not allowed on private function`)
+	}
+	switch sig.Kind {
+	case ReceiveKind:
+		if sig.Visibility != External || sig.StateMutability != Payable {
+			return fmt.Errorf(`receive function must be declared "external payable"`)
+		}
+	case FallbackKind:
+		if sig.Visibility != External {
+			return fmt.Errorf(`fallback function must be declared "external"`)
+		}
+		if sig.StateMutability == View || sig.StateMutability == Pure {
+			return fmt.Errorf(`fallback function must be payable or non-payable, not %q`, mutability)
+		}
+	}
+	return nil
+}
+
 // Signature represents a signature of a function, constructor, fallback,
 // receive, event or error.
 type Signature struct {
@@ -285,14 +785,73 @@ type Signature struct {
 	// fallback, receive and constructor kinds.
 	Name string
 
+	// Scope is an optional name of the contract or interface that declares
+	// the function, event or error, e.g. "IERC20" for
+	// "IERC20.transfer(address,uint256)" parsed with the WithScopedNames
+	// option. It is not a part of the canonical form, so it does not affect
+	// the selector.
+	Scope string
+
 	// Inputs is the list of input parameters.
 	Inputs []Parameter
 
 	// Outputs is the list of output parameters.
 	Outputs []Parameter
 
-	// Modifiers is the list of function modifiers.
+	// Modifiers is the list of names of the function modifiers in the order
+	// in which they appear in the signature, e.g. "external" or "onlyRole".
+	// Argument lists are stored in the ParsedModifiers field.
 	Modifiers []string
+
+	// ParsedModifiers is the list of modifiers along with their argument
+	// lists, one for every name in the Modifiers field, e.g.
+	// {Name: "onlyRole", Args: ["ADMIN"]} for "onlyRole(ADMIN)". The parser
+	// always sets both fields.
+	//
+	// If ParsedModifiers is not nil, it is used to format the signature, so
+	// both fields must be updated when the modifiers are changed. If it is
+	// nil, e.g. in signatures created by hand, the Modifiers field is used
+	// instead.
+	ParsedModifiers []Modifier
+
+	// StateMutability is the state mutability declared by the modifiers,
+	// e.g. View for "view" or "constant". It is UnspecifiedMutability if none
+	// of the modifiers declares the mutability. The parser sets it and keeps
+	// the mutability modifiers in the Modifiers list as well.
+	StateMutability StateMutability
+
+	// Visibility is the visibility declared by the modifiers, e.g. External
+	// for "external". It is UnspecifiedVisibility if none of the modifiers
+	// declares the visibility. Like the state mutability, the visibility
+	// modifier is kept in the Modifiers list as well.
+	Visibility Visibility
+
+	// Anonymous indicates whether the event is declared as anonymous. It must
+	// be false for signatures other than events and signatures of unknown
+	// kind. The "anonymous" keyword is not stored in the Modifiers list.
+	Anonymous bool
+}
+
+// Modifier is a modifier of a signature, split into the name and the list
+// of arguments, see the Signature.ParsedModifiers field.
+type Modifier struct {
+	// Name is the modifier name, e.g. "onlyRole" or "view".
+	Name string
+
+	// Args is the list of arguments of a modifier invocation, as written in
+	// the signature. For the "override" modifier, it is the list of base
+	// contracts. It is nil if the modifier has no argument list, and empty
+	// if the list is empty, e.g. "onlyOwner()".
+	Args []string
+}
+
+// String returns the modifier as it appears in the signature, e.g.
+// "onlyRole(ADMIN, msg.sender)".
+func (m Modifier) String() string {
+	if m.Args == nil {
+		return m.Name
+	}
+	return m.Name + "(" + strings.Join(m.Args, ", ") + ")"
 }
 
 // Parameter represents an argument or return value.
@@ -319,15 +878,96 @@ type Parameter struct {
 	// DataLocation indicates the data location of the argument. It should be
 	// UnspecifiedLocation for types other than function and constructor.
 	DataLocation DataLocation
+
+	// Payable indicates whether the address type is declared as
+	// "address payable". It must be false for types other than address.
+	// The ABI type of a payable address is a plain address.
+	Payable bool
+
+	// Mapping is the key and value types of a mapping, in which case Type
+	// is "mapping". It is only set for struct fields parsed with the
+	// KeepMappings policy, because mappings are not valid ABI types.
+	Mapping *Mapping
+
+	// Function is the signature of a function type, in which case Type is
+	// "function", e.g. "function(uint256) external returns (bool)".
+	Function *FunctionType
+}
+
+// FunctionType represents the inputs, outputs, visibility and state
+// mutability of a function type. Only external function types are valid
+// ABI types. If the visibility is not specified, the function type is
+// internal.
+type FunctionType struct {
+	Inputs          []Parameter
+	Outputs         []Parameter
+	Visibility      Visibility
+	StateMutability StateMutability
+}
+
+// String returns the string representation of the function type, e.g.
+// "function(uint256) external view returns (bool)".
+func (f FunctionType) String() string {
+	var buf strings.Builder
+	buf.WriteString("function(")
+	for i, c := range f.Inputs {
+		buf.WriteString(c.String())
+		if i < len(f.Inputs)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteByte(')')
+	if f.Visibility != UnspecifiedVisibility {
+		buf.WriteByte(' ')
+		buf.WriteString(f.Visibility.String())
+	}
+	if f.StateMutability != UnspecifiedMutability && f.StateMutability != NonPayable {
+		buf.WriteByte(' ')
+		buf.WriteString(f.StateMutability.String())
+	}
+	if len(f.Outputs) > 0 {
+		buf.WriteString(" returns (")
+		for i, c := range f.Outputs {
+			buf.WriteString(c.String())
+			if i < len(f.Outputs)-1 {
+				buf.WriteString(", ")
+			}
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// Mapping represents the key and value types of a mapping, e.g.
+// "mapping(address owner => uint256 balance)". The key and value may have
+// names. The value may be another mapping.
+type Mapping struct {
+	Key   Parameter
+	Value Parameter
 }
 
 // String returns the string representation of the signature.
 func (s Signature) String() string {
+	return s.Format()
+}
+
+// Format returns the string representation of the signature formatted
+// according to the given options. Without options, it is equivalent to
+// String.
+func (s Signature) Format(opts ...FormatOption) string {
+	var fo formatOptions
+	for _, opt := range opts {
+		opt(&fo)
+	}
+	outputs := s.Outputs
+	if fo.flattenSingleReturn && isSingleUnnamedTuple(outputs) {
+		outputs = outputs[0].Tuple
+	}
 	var buf strings.Builder
 	switch s.Kind {
 	case FunctionKind:
 		buf.WriteString("function ")
-		buf.WriteString(s.Name)
+		buf.WriteString(s.qualifiedName())
 	case ConstructorKind:
 		buf.WriteString("constructor")
 	case FallbackKind:
@@ -336,12 +976,15 @@ func (s Signature) String() string {
 		buf.WriteString("receive")
 	case EventKind:
 		buf.WriteString("event ")
-		buf.WriteString(s.Name)
+		buf.WriteString(s.qualifiedName())
 	case ErrorKind:
 		buf.WriteString("error ")
-		buf.WriteString(s.Name)
+		buf.WriteString(s.qualifiedName())
+	case ModifierKind:
+		buf.WriteString("modifier ")
+		buf.WriteString(s.qualifiedName())
 	default:
-		buf.WriteString(s.Name)
+		buf.WriteString(s.qualifiedName())
 	}
 	buf.WriteByte('(')
 	for i, c := range s.Inputs {
@@ -351,20 +994,23 @@ func (s Signature) String() string {
 		}
 	}
 	buf.WriteByte(')')
-	if len(s.Modifiers) > 0 {
+	if s.Anonymous {
+		buf.WriteString(" anonymous")
+	}
+	if mods := s.modifiers(); len(mods) > 0 {
 		buf.WriteString(" ")
-		for i, m := range s.Modifiers {
-			buf.WriteString(m)
-			if i < len(s.Modifiers)-1 {
+		for i, m := range mods {
+			buf.WriteString(m.String())
+			if i < len(mods)-1 {
 				buf.WriteString(" ")
 			}
 		}
 	}
-	if len(s.Outputs) > 0 {
+	if len(outputs) > 0 {
 		buf.WriteString(" returns (")
-		for i, c := range s.Outputs {
+		for i, c := range outputs {
 			buf.WriteString(c.String())
-			if i < len(s.Outputs)-1 {
+			if i < len(outputs)-1 {
 				buf.WriteString(", ")
 			}
 		}
@@ -373,11 +1019,167 @@ func (s Signature) String() string {
 	return buf.String()
 }
 
+// Overrides returns the list of base contracts of the "override" modifier,
+// e.g. ["A", "B"] for "override(A, B)". The ok result reports whether the
+// signature has the "override" modifier at all, in which case bases is nil
+// if the modifier has no list of base contracts.
+func (s Signature) Overrides() (bases []string, ok bool) {
+	for _, mod := range s.modifiers() {
+		if mod.Name == "override" {
+			return mod.Args, true
+		}
+	}
+	return nil, false
+}
+
+// modifiers returns the ParsedModifiers field, or, if it is nil, the
+// modifiers from the Modifiers field without arguments.
+func (s Signature) modifiers() []Modifier {
+	if s.ParsedModifiers != nil {
+		return s.ParsedModifiers
+	}
+	return namedModifiers(s.Modifiers)
+}
+
+// namedModifiers returns the modifiers with the given names and without
+// arguments.
+func namedModifiers(names []string) []Modifier {
+	if len(names) == 0 {
+		return nil
+	}
+	mods := make([]Modifier, len(names))
+	for i, name := range names {
+		mods[i] = Modifier{Name: name}
+	}
+	return mods
+}
+
+// modifierNames returns the names of the given modifiers.
+func modifierNames(mods []Modifier) []string {
+	if len(mods) == 0 {
+		return nil
+	}
+	names := make([]string, len(mods))
+	for i, mod := range mods {
+		names[i] = mod.Name
+	}
+	return names
+}
+
+// declaredMutability returns the state mutability of the signature. The
+// StateMutability field takes precedence over the modifiers, which are only
+// used if the field is not set, e.g. in signatures created manually. The
+// second return value is false if the mutability is not declared.
+func (s Signature) declaredMutability() (StateMutability, bool) {
+	if s.StateMutability != UnspecifiedMutability {
+		return s.StateMutability, true
+	}
+	return findStateMutability(s.Modifiers)
+}
+
+// qualifiedName returns the name prefixed with the scope, if any.
+func (s Signature) qualifiedName() string {
+	if len(s.Scope) > 0 {
+		return s.Scope + "." + s.Name
+	}
+	return s.Name
+}
+
+// InterfaceFingerprint returns the string representation of the signature
+// with all data locations removed.
+//
+// Data locations do not affect the selector or the external interface of
+// a function, so signatures that differ only in data locations have the same
+// fingerprint. Unlike a canonical signature, the fingerprint keeps parameter
+// names, modifiers and return values.
+func (s Signature) InterfaceFingerprint() string {
+	s.Inputs = withoutDataLocations(s.Inputs)
+	s.Outputs = withoutDataLocations(s.Outputs)
+	return s.String()
+}
+
+// InferMutability returns the state mutability declared by the signature
+// modifiers. If there is no such modifier, it returns View for signatures
+// with outputs and NonPayable otherwise.
+//
+// The inferred value is only a best-effort guess meant for display. A
+// function with outputs may as well be pure or modify the state, which
+// cannot be determined from the signature alone.
+func (s Signature) InferMutability() StateMutability {
+	if m, ok := s.declaredMutability(); ok {
+		return m
+	}
+	if len(s.Outputs) > 0 {
+		return View
+	}
+	return NonPayable
+}
+
+// IndexedCount returns the number of indexed inputs of an event. It returns
+// zero for signatures of other kinds.
+func (s Signature) IndexedCount() int {
+	if s.Kind != EventKind {
+		return 0
+	}
+	n := 0
+	for _, input := range s.Inputs {
+		if input.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+// NonIndexedCount returns the number of non-indexed inputs of an event. It
+// returns zero for signatures of other kinds.
+func (s Signature) NonIndexedCount() int {
+	if s.Kind != EventKind {
+		return 0
+	}
+	return len(s.Inputs) - s.IndexedCount()
+}
+
+// FunctionFromTuple returns a function signature with the given name whose
+// inputs are the elements of the input tuple and whose only output is the
+// output parameter. It can be used to wrap a struct returned by ParseStruct
+// as a getter, e.g. "getPair() returns ((address a, address b) Pair)".
+//
+// The input must be a tuple without array dimensions. An empty Parameter
+// may be used for a function without inputs. If the output is an empty
+// Parameter, the function has no outputs.
+func FunctionFromTuple(name string, input Parameter, output Parameter) (Signature, error) {
+	if len(name) == 0 {
+		return Signature{}, fmt.Errorf(`function name must not be empty`)
+	}
+	if len(input.Type) > 0 {
+		return Signature{}, fmt.Errorf(`input must be a tuple, got %q`, input.Type)
+	}
+	if len(input.Arrays) > 0 {
+		return Signature{}, fmt.Errorf(`input must be a tuple, got an array`)
+	}
+	sig := Signature{Kind: FunctionKind, Name: name, Inputs: input.Tuple}
+	if len(output.Type) > 0 || len(output.Tuple) > 0 {
+		sig.Outputs = []Parameter{output}
+	}
+	return sig, nil
+}
+
 // String returns the string representation of the type.
 func (p Parameter) String() string {
 	var buf strings.Builder
-	if len(p.Type) > 0 {
+	if p.Mapping != nil {
+		buf.WriteString("mapping(")
+		buf.WriteString(p.Mapping.Key.String())
+		buf.WriteString(" => ")
+		buf.WriteString(p.Mapping.Value.String())
+		buf.WriteByte(')')
+	} else if p.Function != nil {
+		buf.WriteString(p.Function.String())
+	} else if len(p.Type) > 0 {
 		buf.WriteString(p.Type)
+		if p.Payable {
+			buf.WriteString(" payable")
+		}
 	} else {
 		buf.WriteByte('(')
 		for i, c := range p.Tuple {
@@ -419,9 +1221,121 @@ func (p Parameter) String() string {
 	return buf.String()
 }
 
+// Field returns the tuple element with the given name. It returns false if
+// the parameter is not a tuple or if there is no element with that name.
+func (p Parameter) Field(name string) (Parameter, bool) {
+	for _, c := range p.Tuple {
+		if len(c.Name) > 0 && c.Name == name {
+			return c, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// At returns the tuple element at the given index. It returns false if the
+// parameter is not a tuple or if the index is out of range.
+func (p Parameter) At(index int) (Parameter, bool) {
+	if index < 0 || index >= len(p.Tuple) {
+		return Parameter{}, false
+	}
+	return p.Tuple[index], true
+}
+
+// WithoutArrays returns a copy of the parameter with all array dimensions
+// removed, i.e. the base element type.
+func (p Parameter) WithoutArrays() Parameter {
+	p.Arrays = nil
+	return p
+}
+
+// PopArray removes the outermost array dimension and returns the element
+// parameter along with the size of the removed dimension. The size is -1 for
+// unbounded arrays. If the parameter is not an array, it returns false.
+//
+// For "uint256[2][3]", which is an array of three "uint256[2]" arrays,
+// the outermost dimension is 3.
+func (p Parameter) PopArray() (Parameter, int, bool) {
+	if len(p.Arrays) == 0 {
+		return p, 0, false
+	}
+	n := p.Arrays[len(p.Arrays)-1]
+	p.Arrays = copyArrays(p.Arrays[:len(p.Arrays)-1])
+	if len(p.Arrays) == 0 {
+		p.Arrays = nil
+	}
+	return p, n, true
+}
+
+// PushArray returns a copy of the parameter wrapped in a new outermost
+// array dimension of the given size. Use -1 for unbounded arrays.
+func (p Parameter) PushArray(size int) Parameter {
+	arrays := make([]int, len(p.Arrays), len(p.Arrays)+1)
+	copy(arrays, p.Arrays)
+	p.Arrays = append(arrays, size)
+	return p
+}
+
+// Lookup returns the parameter at the given dotted path, e.g. "order.maker".
+//
+// The first path element is the name of an input parameter or, if there is
+// no such input, the name of an output parameter. Remaining elements refer
+// to tuple elements. Each element may be either a name or a zero-based
+// index, e.g. "order.0".
+func (s Signature) Lookup(path string) (Parameter, bool) {
+	elems := strings.Split(path, ".")
+	root := Parameter{Tuple: s.Inputs}
+	param, ok := root.lookup(elems[0])
+	if !ok {
+		root = Parameter{Tuple: s.Outputs}
+		if param, ok = root.lookup(elems[0]); !ok {
+			return Parameter{}, false
+		}
+	}
+	for _, elem := range elems[1:] {
+		if param, ok = param.lookup(elem); !ok {
+			return Parameter{}, false
+		}
+	}
+	return param, true
+}
+
+// lookup returns the tuple element with the given name or, if elem is
+// a number, at the given index.
+func (p Parameter) lookup(elem string) (Parameter, bool) {
+	if param, ok := p.Field(elem); ok {
+		return param, true
+	}
+	if idx, err := strconv.Atoi(elem); err == nil {
+		return p.At(idx)
+	}
+	return Parameter{}, false
+}
+
+// withoutDataLocations returns a copy of params with the data location
+// of every parameter, including nested tuple elements, removed.
+func withoutDataLocations(params []Parameter) []Parameter {
+	if params == nil {
+		return nil
+	}
+	cpy := make([]Parameter, len(params))
+	for i, p := range params {
+		p.DataLocation = UnspecifiedLocation
+		p.Tuple = withoutDataLocations(p.Tuple)
+		cpy[i] = p
+	}
+	return cpy
+}
+
+// maxDepth is the maximum nesting depth of tuples. It protects the parser
+// against stack exhaustion on malicious inputs.
+const maxDepth = 1024
+
 type parser struct {
-	in  []byte
-	pos int
+	in    []byte
+	pos   int
+	depth int
+	event bool // true while parsing event inputs
+	opts  options
 }
 
 func (p *parser) parseSignature(kind SignatureKind) (Signature, error) {
@@ -439,28 +1353,97 @@ func (p *parser) parseSignature(kind SignatureKind) (Signature, error) {
 	}
 	// Parse name.
 	p.parseWhitespace()
-	sig.Name = string(p.parseName())
+	namePos := p.pos
+	if p.opts.dottedNames {
+		sig.Name = string(p.parseDottedName())
+	} else {
+		sig.Name = string(p.parseName())
+		// Parse the name qualified with a scope, e.g. "IERC20.transfer".
+		if p.opts.scopedNames && len(sig.Name) > 0 && p.peekByte('.') {
+			dot := p.pos
+			p.read()
+			if name := p.parseName(); len(name) > 0 {
+				sig.Scope, sig.Name = sig.Name, string(name)
+			} else {
+				p.pos = dot
+			}
+		}
+	}
 	// Parse inputs.
 	p.parseWhitespace()
-	if sig.Inputs, err = p.parseInputs(); err != nil {
+	if len(sig.Name) > 0 && !p.peekByte('(') && isUnnamedKind(sig.Kind) && (isModifierKeyword(sig.Name) || sig.Name == "override") {
+		// Signatures that cannot have a name may omit the parameter list,
+		// e.g. "fallback external". In that case, the parsed name is the
+		// first modifier. Other words are reported as a name below.
+		sig.Name = ""
+		sig.Scope = ""
+		p.pos = namePos
+	}
+	if sig.Name == "returns" || isModifierKeyword(sig.Name) {
+		// Keywords continue the previous signature in lists of signatures,
+		// so they cannot start a new one.
+		return Signature{}, p.errorAt(namePos, fmt.Errorf(`keyword %q cannot be used as a signature name`, sig.Name))
+	}
+	p.event = sig.Kind == EventKind
+	sig.Inputs, err = p.parseInputs()
+	p.event = false
+	if err != nil {
 		return Signature{}, err
 	}
 	// Parse modifiers.
 	p.parseWhitespace()
-	sig.Modifiers = p.parseModifiers()
+	sig.ParsedModifiers = p.parseModifiers()
 	// Parse outputs.
 	p.parseWhitespace()
+	outputsPos := p.pos
 	if sig.Outputs, err = p.parseOutputs(); err != nil {
 		return Signature{}, err
 	}
+	// Parse modifiers placed after the outputs.
+	if len(sig.Outputs) > 0 {
+		p.parseWhitespace()
+		if p.hasNext() && (isAlpha(p.peek()) || isIdentifierSymbol(p.peek())) {
+			if !p.opts.modifiersAfterReturns {
+				return Signature{}, fmt.Errorf(`unexpected modifier %q after return values, modifiers must be placed before the 'returns' keyword`, p.peekName())
+			}
+			sig.ParsedModifiers = append(sig.ParsedModifiers, p.parseModifiers()...)
+		}
+	}
+	sig.Modifiers = modifierNames(sig.ParsedModifiers)
+	sig.StateMutability, _ = findStateMutability(sig.Modifiers)
+	if sig.Visibility, err = findVisibility(sig.Modifiers, p.opts.strictModifiers); err != nil {
+		return Signature{}, err
+	}
+	if p.opts.strictModifiers {
+		if err := validateModifiers(sig); err != nil {
+			return Signature{}, err
+		}
+	}
+	// Parse the gas limit suffix used by ethers, e.g. "@29000".
+	if p.opts.ethersCompat {
+		p.parseWhitespace()
+		if p.readByte('@') {
+			if _, ok, err := p.parseNumber(); !ok || err != nil {
+				return Signature{}, fmt.Errorf(`invalid gas limit, number expected after '@'`)
+			}
+		}
+	}
 	// Validate signature based on its kind.
 	switch sig.Kind {
 	case ConstructorKind:
 		if len(sig.Name) > 0 {
 			return Signature{}, fmt.Errorf(`unexpected constructor name %q`, sig.Name)
 		}
-		if len(sig.Modifiers) > 0 {
-			return Signature{}, fmt.Errorf(`unexpected constructor modifiers`)
+		for _, mod := range sig.Modifiers {
+			switch mod {
+			case "payable":
+			case "public", "internal":
+				if p.opts.versionProfile != LegacySolidity {
+					return Signature{}, fmt.Errorf(`constructor visibility %q is only allowed before Solidity 0.7.0, see the LegacySolidity profile`, mod)
+				}
+			default:
+				return Signature{}, fmt.Errorf(`modifier %q not allowed on constructor`, mod)
+			}
 		}
 		if len(sig.Outputs) > 0 {
 			return Signature{}, fmt.Errorf(`unexpected constructor outputs`)
@@ -476,6 +1459,14 @@ func (p *parser) parseSignature(kind SignatureKind) (Signature, error) {
 		if !validInOut && len(sig.Outputs) > 0 {
 			return Signature{}, fmt.Errorf(`unexpected fallback outputs`)
 		}
+		if validInOut && p.opts.strictFallback {
+			if sig.Inputs[0].DataLocation != CallData {
+				return Signature{}, fmt.Errorf(`fallback input must be "bytes calldata"`)
+			}
+			if sig.Outputs[0].DataLocation != Memory {
+				return Signature{}, fmt.Errorf(`fallback output must be "bytes memory"`)
+			}
+		}
 	case ReceiveKind:
 		if len(sig.Name) > 0 {
 			return Signature{}, fmt.Errorf(`unexpected receive name %q`, sig.Name)
@@ -487,32 +1478,77 @@ func (p *parser) parseSignature(kind SignatureKind) (Signature, error) {
 			return Signature{}, fmt.Errorf(`unexpected receive outputs`)
 		}
 	case EventKind:
-		if len(sig.Inputs) == 0 {
-			return Signature{}, fmt.Errorf(`event must have inputs`)
+		// Inputs are checked first, so that the missing parameters are
+		// reported even if the modifiers are invalid as well.
+		if len(sig.Inputs) == 0 && !p.opts.ethersCompat {
+			return Signature{}, fmt.Errorf(`event %q must declare at least one parameter`, sig.Name)
 		}
 		if len(sig.Outputs) > 0 {
-			return Signature{}, fmt.Errorf(`unexpected event outputs`)
-		}
-		if !(len(sig.Modifiers) == 0 || (len(sig.Modifiers) == 1 && sig.Modifiers[0] == "anonymous")) {
-			return Signature{}, fmt.Errorf(`unexpected event modifiers`)
+			return Signature{}, p.errorAt(outputsPos, fmt.Errorf(`event signatures cannot declare return values`))
 		}
-		for _, input := range sig.Inputs {
-			if input.DataLocation != UnspecifiedLocation {
-				return Signature{}, fmt.Errorf(`unexpected event input data location`)
+		for _, mod := range sig.Modifiers {
+			if mod != "anonymous" {
+				return Signature{}, fmt.Errorf(`modifier %q not allowed on event`, mod)
 			}
+			if sig.Anonymous {
+				return Signature{}, fmt.Errorf(`duplicate event modifier %q`, mod)
+			}
+			sig.Anonymous = true
+		}
+		sig.Modifiers = nil
+		sig.ParsedModifiers = nil
+		if loc := findDataLocation(sig.Inputs); loc != UnspecifiedLocation {
+			return Signature{}, fmt.Errorf(`unexpected data location %q in event input`, loc)
+		}
+		maxIndexed := 3
+		if sig.Anonymous {
+			maxIndexed = 4
+		}
+		if n := sig.IndexedCount(); n > maxIndexed {
+			return Signature{}, fmt.Errorf(`too many indexed event inputs: %d, at most %d allowed`, n, maxIndexed)
 		}
 	case ErrorKind:
 		if len(sig.Outputs) > 0 {
-			return Signature{}, fmt.Errorf(`unexpected error outputs`)
+			return Signature{}, p.errorAt(outputsPos, fmt.Errorf(`error signatures cannot declare return values`))
 		}
 		if len(sig.Modifiers) > 0 {
-			return Signature{}, fmt.Errorf(`unexpected error modifiers`)
+			return Signature{}, fmt.Errorf(`modifier %q not allowed on error`, sig.Modifiers[0])
 		}
-		for _, input := range sig.Inputs {
-			if input.DataLocation != UnspecifiedLocation {
-				return Signature{}, fmt.Errorf(`unexpected error input data location`)
+		if loc := findDataLocation(sig.Inputs); loc != UnspecifiedLocation {
+			return Signature{}, fmt.Errorf(`unexpected data location %q in error input`, loc)
+		}
+	case ModifierKind:
+		if len(sig.Name) == 0 {
+			return Signature{}, fmt.Errorf(`modifier name expected`)
+		}
+		if len(sig.Outputs) > 0 {
+			return Signature{}, p.errorAt(outputsPos, fmt.Errorf(`modifier signatures cannot declare return values`))
+		}
+		for _, mod := range sig.Modifiers {
+			if mod != "virtual" && mod != "override" {
+				return Signature{}, fmt.Errorf(`modifier %q not allowed on modifier`, mod)
+			}
+		}
+	}
+	if sig.Kind == UnknownKind && hasModifier(sig.Modifiers, "anonymous") {
+		// Like the indexed flag, the anonymous modifier is tolerated if the
+		// kind is unknown, because the signature may describe an event.
+		var mods []Modifier
+		for _, mod := range sig.ParsedModifiers {
+			if mod.Name != "anonymous" {
+				mods = append(mods, mod)
+				continue
+			}
+			if sig.Anonymous {
+				return Signature{}, fmt.Errorf(`duplicate modifier %q`, mod.Name)
 			}
+			sig.Anonymous = true
 		}
+		sig.Modifiers = modifierNames(mods)
+		sig.ParsedModifiers = mods
+	}
+	if sig.Kind != EventKind && hasModifier(sig.Modifiers, "anonymous") {
+		return Signature{}, fmt.Errorf(`modifier "anonymous" is only allowed on events`)
 	}
 	if sig.Kind != UnknownKind && sig.Kind != EventKind {
 		for _, input := range sig.Inputs {
@@ -526,29 +1562,81 @@ func (p *parser) parseSignature(kind SignatureKind) (Signature, error) {
 			return Signature{}, fmt.Errorf(`unexpected indexed flag`)
 		}
 	}
-	return sig, nil
+	// The indexed flag is only valid on top-level event inputs.
+	for _, params := range [][]Parameter{sig.Inputs, sig.Outputs} {
+		for _, param := range params {
+			if hasIndexed(param.Tuple) {
+				return Signature{}, fmt.Errorf(`unexpected indexed flag in tuple element`)
+			}
+		}
+	}
+	return sig, nil
+}
+
+// hasIndexed returns true if any of params, including nested tuple elements,
+// has the indexed flag set.
+func hasIndexed(params []Parameter) bool {
+	for _, param := range params {
+		if param.Indexed || hasIndexed(param.Tuple) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnnamedKind returns true for signature kinds that cannot have a name.
+func isUnnamedKind(kind SignatureKind) bool {
+	return kind == ConstructorKind || kind == FallbackKind || kind == ReceiveKind
+}
+
+// findDataLocation returns the first data location specified in params,
+// including nested tuple elements. It returns UnspecifiedLocation if none of
+// the parameters has a data location.
+func findDataLocation(params []Parameter) DataLocation {
+	for _, param := range params {
+		if param.DataLocation != UnspecifiedLocation {
+			return param.DataLocation
+		}
+		if loc := findDataLocation(param.Tuple); loc != UnspecifiedLocation {
+			return loc
+		}
+	}
+	return UnspecifiedLocation
 }
 
-// parseSignatureKind parses signature kind.
+// parseSignatureKind parses the signature kind keyword. The keyword must be
+// a whole word, so that names like "errors" or "modifiers" are not mistaken
+// for keywords. If there is no keyword, UnknownKind is returned and the
+// position is not changed.
 func (p *parser) parseSignatureKind() SignatureKind {
-	switch {
-	case p.readBytes([]byte("function")):
-		return FunctionKind
-	case p.readBytes([]byte("constructor")):
-		return ConstructorKind
-	case p.readBytes([]byte("fallback")):
-		return FallbackKind
-	case p.readBytes([]byte("receive")):
-		return ReceiveKind
-	case p.readBytes([]byte("event")):
-		return EventKind
-	case p.readBytes([]byte("error")):
-		return ErrorKind
-	}
-	return UnknownKind
+	pos := p.pos
+	var kind SignatureKind
+	switch string(p.parseName()) {
+	case "function":
+		kind = FunctionKind
+	case "constructor":
+		kind = ConstructorKind
+	case "fallback":
+		kind = FallbackKind
+	case "receive":
+		kind = ReceiveKind
+	case "event":
+		kind = EventKind
+	case "error":
+		kind = ErrorKind
+	case "modifier":
+		kind = ModifierKind
+	}
+	if kind == UnknownKind {
+		p.pos = pos
+	}
+	return kind
 }
 
 func (p *parser) parseInputs() ([]Parameter, error) {
+	if p.peekTuple() {
+		return nil, fmt.Errorf(`the 'tuple' keyword cannot be used for the input parameter list, use '(' instead`)
+	}
 	if p.peekByte('(') {
 		// Parameter list have exactly the same syntax as composite type, except
 		// that it cannot have arrays.
@@ -571,12 +1659,18 @@ func (p *parser) parseOutputs() ([]Parameter, error) {
 		returnsKeyword = true
 		p.parseWhitespace()
 	}
+	if p.peekTuple() {
+		return nil, fmt.Errorf(`the 'tuple' keyword cannot be used for the output parameter list, use '(' instead`)
+	}
 	if returnsKeyword && !p.peekByte('(') {
 		if !p.hasNext() {
 			return nil, fmt.Errorf(`unexpected end of input, expected '(' after 'returns' keyword`)
 		}
 		return nil, fmt.Errorf(`unexpected character %q, expected '(' after 'returns' keyword`, p.peek())
 	}
+	if !returnsKeyword && p.opts.requireReturnsKeyword && p.peekByte('(') {
+		return nil, fmt.Errorf(`outputs must be introduced with the 'returns' keyword`)
+	}
 	if p.peekByte('(') {
 		// Return types list have exactly the same syntax as composite type,
 		// except that it cannot have arrays.
@@ -617,8 +1711,26 @@ func (p *parser) parseStruct() (Parameter, error) {
 		if p.readByte('}') {
 			break
 		}
-		// Parse field type.
-		field, err := p.parseElementaryType()
+		// Parse field type, which is either an elementary type, a struct
+		// name or an inline tuple.
+		var (
+			field Parameter
+			err   error
+		)
+		if p.peekMapping() {
+			if p.opts.mappingPolicy == RejectMappings {
+				return Parameter{}, p.errorAt(p.pos, fmt.Errorf(`unexpected mapping field, mappings are not valid ABI types`))
+			}
+			field, err = p.parseMapping()
+		} else if p.peekByte('(') || p.peekTuple() {
+			if field, err = p.parseCompositeType(); err == nil {
+				err = p.checkStructTuple(field.Tuple)
+			}
+		} else if p.peekFunctionType() {
+			field, err = p.parseFunctionType()
+		} else {
+			field, err = p.parseElementaryType()
+		}
 		if err != nil {
 			return Parameter{}, err
 		}
@@ -626,9 +1738,14 @@ func (p *parser) parseStruct() (Parameter, error) {
 		// Parse field name.
 		field.Name = string(p.parseName())
 		if len(field.Name) == 0 {
-			return Parameter{}, fmt.Errorf(`unexpected end of input, field name expected`)
+			if !p.hasNext() {
+				return Parameter{}, fmt.Errorf(`unexpected end of input, field name expected`)
+			}
+			return Parameter{}, fmt.Errorf(`unexpected character %q, field name expected`, p.peek())
+		}
+		if field.Mapping == nil || p.opts.mappingPolicy == KeepMappings {
+			s.Tuple = append(s.Tuple, field)
 		}
-		s.Tuple = append(s.Tuple, field)
 		p.parseWhitespace()
 		// Parse field separator.
 		if !p.readByte(';') {
@@ -641,17 +1758,147 @@ func (p *parser) parseStruct() (Parameter, error) {
 	return s, nil
 }
 
-// parseModifiers parses method modifiers.
-func (p *parser) parseModifiers() []string {
-	var mods []string
+// parseUserDefinedType parses a user-defined value type declaration, e.g.
+// "type Price is uint256".
+func (p *parser) parseUserDefinedType() (Parameter, error) {
+	if !p.readBytes([]byte("type")) || !p.hasNext() || !isWhitespace(p.peek()) {
+		return Parameter{}, fmt.Errorf(`'type' keyword expected`)
+	}
+	p.parseWhitespace()
+	name := string(p.parseName())
+	if len(name) == 0 {
+		return Parameter{}, p.expectedError("type name")
+	}
+	p.parseWhitespace()
+	if p.peekName() != "is" {
+		return Parameter{}, p.expectedError("'is' keyword")
+	}
+	p.parseName()
+	p.parseWhitespace()
+	pos := p.pos
+	typ, err := p.parseElementaryType()
+	if err != nil {
+		return Parameter{}, err
+	}
+	dynamic, err := isDynamicElementaryType(typ.Type)
+	if err != nil || dynamic || len(typ.Arrays) > 0 {
+		return Parameter{}, p.errorAt(pos, fmt.Errorf(`invalid underlying type, elementary value type expected`))
+	}
+	typ.Name = name
+	return typ, nil
+}
+
+// peekMapping returns true if the input at the current position is
+// a mapping type.
+func (p *parser) peekMapping() bool {
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	if !p.readBytes([]byte("mapping")) {
+		return false
+	}
+	p.parseWhitespace()
+	return p.peekByte('(')
+}
+
+// parseMapping parses a mapping type, e.g. "mapping(address => uint256)".
+// Names of the key and value, allowed since Solidity 0.8.18, are parsed
+// as well.
+func (p *parser) parseMapping() (Parameter, error) {
+	p.readBytes([]byte("mapping"))
+	p.parseWhitespace()
+	open := p.pos
+	if !p.readByte('(') {
+		return Parameter{}, fmt.Errorf(`unexpected character %q, '(' expected`, p.peek())
+	}
+	if p.depth >= maxDepth {
+		return Parameter{}, p.errorAt(open, fmt.Errorf(`maximum tuple nesting depth of %d exceeded`, maxDepth))
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	var m Mapping
+	p.parseWhitespace()
+	key, err := p.parseElementaryType()
+	if err != nil {
+		return Parameter{}, err
+	}
+	if len(key.Type) == 0 || len(key.Arrays) > 0 {
+		return Parameter{}, p.errorAt(open+1, fmt.Errorf(`invalid mapping key type`))
+	}
+	m.Key = key
+	p.parseWhitespace()
+	m.Key.Name = string(p.parseName())
+	p.parseWhitespace()
+	if !p.readBytes([]byte("=>")) {
+		if !p.hasNext() {
+			return Parameter{}, p.errorAt(open, fmt.Errorf(`unclosed '('`))
+		}
+		return Parameter{}, fmt.Errorf(`unexpected character %q, '=>' expected`, p.peek())
+	}
+	p.parseWhitespace()
+	if p.peekMapping() {
+		m.Value, err = p.parseMapping()
+	} else {
+		m.Value, err = p.parseElementaryType()
+		if err == nil && len(m.Value.Type) == 0 {
+			err = p.errorAt(p.pos, fmt.Errorf(`invalid mapping value type`))
+		}
+	}
+	if err != nil {
+		return Parameter{}, err
+	}
+	p.parseWhitespace()
+	m.Value.Name = string(p.parseName())
+	p.parseWhitespace()
+	if !p.readByte(')') {
+		if !p.hasNext() {
+			return Parameter{}, p.errorAt(open, fmt.Errorf(`unclosed '('`))
+		}
+		return Parameter{}, fmt.Errorf(`unexpected character %q, ')' expected`, p.peek())
+	}
+	return Parameter{Type: "mapping", Mapping: &m}, nil
+}
+
+// checkStructTuple verifies the elements of an inline tuple used as a struct
+// field, including nested tuples. Indexed flags and data locations are not
+// valid in struct definitions. If the WithRequireTupleFieldNames option is
+// enabled, the elements must be named.
+func (p *parser) checkStructTuple(params []Parameter) error {
+	for i, param := range params {
+		switch {
+		case param.Indexed:
+			return fmt.Errorf(`unexpected indexed flag in tuple element`)
+		case param.DataLocation != UnspecifiedLocation:
+			return fmt.Errorf(`unexpected data location %q in tuple element`, param.DataLocation)
+		case p.opts.requireTupleFieldNames && len(param.Name) == 0:
+			return fmt.Errorf(`tuple field at index %d requires a name`, i)
+		}
+		if err := p.checkStructTuple(param.Tuple); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseModifiers parses method modifiers along with their argument lists.
+func (p *parser) parseModifiers() []Modifier {
+	var mods []Modifier
 	for {
-		if !p.hasNext() || p.peekByte('(') || p.peekBytes([]byte("returns")) {
+		if !p.hasNext() || p.peekByte('(') || p.peekBytes([]byte("returns")) || p.peekTuple() {
 			break
 		}
-		mod := string(p.parseName())
-		if len(mod) == 0 {
+		mod := Modifier{Name: string(p.parseName())}
+		if len(mod.Name) == 0 {
 			break
 		}
+		if mod.Name == "override" {
+			if bases, ok := p.parseOverrideBases(); ok {
+				mod.Args = bases
+			}
+		} else if !isModifierKeyword(mod.Name) {
+			if args, ok := p.parseModifierArgs(); ok {
+				mod.Args = args
+			}
+		}
 		mods = append(mods, mod)
 		if !p.hasNext() || !isWhitespace(p.peek()) {
 			break
@@ -661,6 +1908,100 @@ func (p *parser) parseModifiers() []string {
 	return mods
 }
 
+// parseOverrideBases parses the list of base contracts that follows the
+// "override" modifier, e.g. "(A, B)". The list must immediately follow the
+// modifier, otherwise it would be indistinguishable from the return values
+// in signatures without the "returns" keyword. For the same reason, a list
+// starting with an elementary type, as in "override(uint256)", is not
+// a list of base contracts, but the return values. If there is no valid
+// list, false is returned and the position is not changed.
+func (p *parser) parseOverrideBases() ([]string, bool) {
+	pos := p.pos
+	if !p.readByte('(') {
+		return nil, false
+	}
+	var bases []string
+	for {
+		p.parseWhitespace()
+		name := p.parseDottedName()
+		if len(name) == 0 || (len(bases) == 0 && isElementaryType(string(name))) {
+			p.pos = pos
+			return nil, false
+		}
+		bases = append(bases, string(name))
+		p.parseWhitespace()
+		if p.readByte(')') {
+			return bases, true
+		}
+		if !p.readByte(',') {
+			p.pos = pos
+			return nil, false
+		}
+	}
+}
+
+// parseModifierArgs parses the argument list of a modifier invocation, e.g.
+// "(DEFAULT_ADMIN_ROLE, msg.sender)". Like the list of override bases, the
+// argument list must immediately follow the modifier name, and a list
+// starting with an elementary type, as in "nonReentrant(uint256)", is not
+// an argument list, but the return values. Type conversions, such as
+// "bytes32(0)", are arguments. Arguments are arbitrary expressions, which
+// are returned as written, without surrounding whitespaces. If there is no
+// valid list, false is returned and the position is not changed.
+func (p *parser) parseModifierArgs() ([]string, bool) {
+	pos := p.pos
+	if !p.readByte('(') {
+		return nil, false
+	}
+	p.parseWhitespace()
+	if isElementaryType(string(p.parseName())) && !p.peekByte('(') {
+		p.pos = pos
+		return nil, false
+	}
+	p.pos = pos + 1
+	args := []string{}
+	start, depth := p.pos, 0
+	for p.hasNext() {
+		if p.skipString() {
+			continue
+		}
+		switch p.read() {
+		case '(', '[':
+			depth++
+		case ']':
+			depth--
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			arg := strings.TrimSpace(string(p.in[start : p.pos-1]))
+			if len(arg) > 0 || len(args) > 0 {
+				args = append(args, arg)
+			}
+			return args, true
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(string(p.in[start:p.pos-1])))
+				start = p.pos
+			}
+		}
+	}
+	p.pos = pos
+	return nil, false
+}
+
+// isModifierKeyword returns true if s is a keyword that may appear among
+// the modifiers of a signature, as opposed to the name of a user-defined
+// modifier.
+func isModifierKeyword(s string) bool {
+	switch s {
+	case "external", "public", "internal", "private", "pure", "view", "payable", "nonpayable", "constant", "virtual", "anonymous":
+		return true
+	}
+	return false
+}
+
 // parseParameter parses a single argument or return value.
 func (p *parser) parseParameter() (Parameter, error) {
 	var (
@@ -674,11 +2015,31 @@ func (p *parser) parseParameter() (Parameter, error) {
 	switch {
 	case !p.hasNext():
 		return Parameter{}, fmt.Errorf(`unexpected end of input, type expected`)
-	case p.peekByte('(') || p.peekBytes([]byte("tuple(")):
+	case p.peekInlineStruct():
+		return Parameter{}, fmt.Errorf(`inline struct definitions are not valid in signatures; use a tuple`)
+	case p.peekByte('(') || p.peekTuple():
 		arg, err = p.parseCompositeType()
 		if err != nil {
 			return Parameter{}, err
 		}
+		if p.opts.requireTupleFieldNames {
+			for i, c := range arg.Tuple {
+				if len(c.Name) == 0 {
+					return Parameter{}, fmt.Errorf(`tuple field at index %d requires a name`, i)
+				}
+			}
+		}
+	case p.peekFunctionType():
+		arg, err = p.parseFunctionType()
+		if err != nil {
+			return Parameter{}, err
+		}
+		pos := p.pos
+		p.parseWhitespace()
+		if next := p.peekName(); next == "public" || next == "private" {
+			return Parameter{}, fmt.Errorf(`function types cannot be %s, only internal or external`, next)
+		}
+		p.pos = pos
 	case isAlpha(p.peek()) || isIdentifierSymbol(p.peek()):
 		arg, err = p.parseElementaryType()
 		if err != nil {
@@ -714,24 +2075,71 @@ func (p *parser) parseParameter() (Parameter, error) {
 			arg.Name = string(p.parseName())
 		}
 	}
+	if p.event && p.opts.strictEventSyntax {
+		if err := p.checkEventParameter(arg); err != nil {
+			return Parameter{}, err
+		}
+	}
 	return arg, err
 }
 
+// checkEventParameter verifies that the event parameter that was just parsed
+// follows the "type [indexed] [name]" grammar.
+func (p *parser) checkEventParameter(arg Parameter) error {
+	if arg.DataLocation != UnspecifiedLocation {
+		return fmt.Errorf(`unexpected data location %q, event parameters must follow the "type [indexed] [name]" order`, arg.DataLocation)
+	}
+	if isParameterKeyword(arg.Name) {
+		return fmt.Errorf(`unexpected keyword %q, event parameters must follow the "type [indexed] [name]" order`, arg.Name)
+	}
+	pos := p.pos
+	p.parseWhitespace()
+	next := p.peekName()
+	p.pos = pos
+	if len(next) > 0 {
+		return fmt.Errorf(`unexpected %q after parameter name %q, event parameters must follow the "type [indexed] [name]" order`, next, arg.Name)
+	}
+	return nil
+}
+
+// peekInlineStruct returns true if the input at the current position is
+// a struct definition, e.g. "struct { uint256 a; }".
+func (p *parser) peekInlineStruct() bool {
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	if !p.readBytes([]byte("struct")) {
+		return false
+	}
+	p.parseWhitespace()
+	p.parseName()
+	p.parseWhitespace()
+	return p.peekByte('{')
+}
+
 // parseCompositeType parses composite type argument along with optional array
 // declarations.
 func (p *parser) parseCompositeType() (Parameter, error) {
-	if !p.readByte('(') && !p.readBytes([]byte("tuple(")) {
+	if !p.readByte('(') && !p.readTuple() {
 		if !p.hasNext() {
 			return Parameter{}, fmt.Errorf(`unexpected end of input, 'tuple(' or '(' expected`)
 		}
 		return Parameter{}, fmt.Errorf(`unexpected character %q, 'tuple(' or '(' expected`, p.peek())
 	}
 	var arg Parameter
+	open := p.pos - 1 // position of the opening parenthesis
+	if p.depth >= maxDepth {
+		return Parameter{}, p.errorAt(open, fmt.Errorf(`maximum tuple nesting depth of %d exceeded`, maxDepth))
+	}
+	p.depth++
+	defer func() { p.depth-- }()
 	p.parseWhitespace()
 	// Parse components, but only if composite type is not empty.
 	if !p.readByte(')') {
 		for {
 			p.parseWhitespace()
+			if !p.hasNext() {
+				return Parameter{}, p.errorAt(open, fmt.Errorf(`unclosed '('`))
+			}
 			comp, err := p.parseParameter()
 			if err != nil {
 				return Parameter{}, err
@@ -745,13 +2153,13 @@ func (p *parser) parseCompositeType() (Parameter, error) {
 				break
 			}
 			if !p.hasNext() {
-				return Parameter{}, fmt.Errorf(`unexpected end of input, ',' or ')' expected`)
+				return Parameter{}, p.errorAt(open, fmt.Errorf(`unclosed '('`))
 			}
 			return Parameter{}, fmt.Errorf(`unexpected character %q, ',' or ')' expected`, p.peek())
 		}
 	}
 	// Parse array declarations, if any.
-	if p.peekByte('[') {
+	if p.peekArray() {
 		arr, err := p.parseArray()
 		if err != nil {
 			return Parameter{}, err
@@ -761,6 +2169,88 @@ func (p *parser) parseCompositeType() (Parameter, error) {
 	return arg, nil
 }
 
+// peekFunctionType returns true if the input at the current position is
+// a function type, e.g. "function(uint256) external".
+func (p *parser) peekFunctionType() bool {
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	if !p.readBytes([]byte("function")) {
+		return false
+	}
+	p.parseWhitespace()
+	return p.peekByte('(')
+}
+
+// parseFunctionType parses a function type along with optional array
+// declaration, e.g. "function(uint256) external view returns (bool)[]".
+// The "returns" keyword is required, otherwise the return values would be
+// indistinguishable from the next parameter.
+func (p *parser) parseFunctionType() (Parameter, error) {
+	p.readBytes([]byte("function"))
+	p.parseWhitespace()
+	var fn FunctionType
+	arg := Parameter{Type: "function", Function: &fn}
+	// Array dimensions directly after the parameter lists belong to the
+	// function type, e.g. "function(uint256)[]" is an array of functions.
+	inputs, err := p.parseCompositeType()
+	if err != nil {
+		return Parameter{}, err
+	}
+	fn.Inputs, arg.Arrays = inputs.Tuple, inputs.Arrays
+	if len(arg.Arrays) > 0 {
+		return arg, nil
+	}
+	var visibility, mutability string
+	for done := false; !done; {
+		pos := p.pos
+		p.parseWhitespace()
+		switch mod := p.peekName(); mod {
+		case "external", "internal":
+			if len(visibility) > 0 {
+				return Parameter{}, fmt.Errorf(`multiple visibility modifiers in function type: %q and %q`, visibility, mod)
+			}
+			visibility = mod
+			fn.Visibility, _ = findVisibility([]string{mod}, false)
+		case "payable", "view", "pure":
+			if len(mutability) > 0 {
+				return Parameter{}, fmt.Errorf(`multiple state mutability modifiers in function type: %q and %q`, mutability, mod)
+			}
+			mutability = mod
+			fn.StateMutability, _ = findStateMutability([]string{mod})
+		default:
+			// The "public" and "private" keywords may follow the function
+			// type in state variable declarations, where they refer to the
+			// variable.
+			p.pos = pos
+			done = true
+			continue
+		}
+		p.parseName()
+	}
+	pos := p.pos
+	p.parseWhitespace()
+	if p.peekName() != "returns" {
+		p.pos = pos
+		if p.peekArray() {
+			if arg.Arrays, err = p.parseArray(); err != nil {
+				return Parameter{}, err
+			}
+		}
+		return arg, nil
+	}
+	p.parseName()
+	p.parseWhitespace()
+	if !p.peekByte('(') {
+		return Parameter{}, fmt.Errorf(`'(' expected after 'returns' keyword in function type`)
+	}
+	outputs, err := p.parseCompositeType()
+	if err != nil {
+		return Parameter{}, err
+	}
+	fn.Outputs, arg.Arrays = outputs.Tuple, outputs.Arrays
+	return arg, nil
+}
+
 // parseElementaryType parses elementary type along with optional array
 // declaration.
 func (p *parser) parseElementaryType() (Parameter, error) {
@@ -780,8 +2270,11 @@ func (p *parser) parseElementaryType() (Parameter, error) {
 		break
 	}
 	arg.Type = string(p.in[pos:p.pos])
+	if arg.Type == "address" {
+		arg.Payable = p.parsePayable()
+	}
 	// Parse array declaration, if any.
-	if p.peekByte('[') {
+	if p.peekArray() {
 		arr, err := p.parseArray()
 		if err != nil {
 			return Parameter{}, err
@@ -791,9 +2284,30 @@ func (p *parser) parseElementaryType() (Parameter, error) {
 	return arg, nil
 }
 
-// parseWhitespace parses whitespaces.
+// parsePayable parses the "payable" keyword of the "address payable" type.
+// It returns false and does not change the position if there is no such
+// keyword.
+func (p *parser) parsePayable() bool {
+	pos := p.pos
+	if !p.hasNext() || !isWhitespace(p.peek()) {
+		return false
+	}
+	p.parseWhitespace()
+	if p.peekName() != "payable" {
+		p.pos = pos
+		return false
+	}
+	p.parseName()
+	return true
+}
+
+// parseWhitespace parses whitespaces. If the WithComments option is enabled,
+// comments are parsed as well.
 func (p *parser) parseWhitespace() {
 	for p.hasNext() {
+		if p.opts.comments && p.parseComment() {
+			continue
+		}
 		if !isWhitespace(p.peek()) {
 			break
 		}
@@ -801,6 +2315,27 @@ func (p *parser) parseWhitespace() {
 	}
 }
 
+// parseComment parses a single line or block comment. It returns false if
+// there is no comment at the current position. Unterminated block comments
+// are not treated as comments.
+func (p *parser) parseComment() bool {
+	switch {
+	case p.peekBytes([]byte("//")):
+		for p.hasNext() && p.peek() != '\n' {
+			p.read()
+		}
+		return true
+	case p.peekBytes([]byte("/*")):
+		end := bytes.Index(p.in[p.pos+2:], []byte("*/"))
+		if end < 0 {
+			return false
+		}
+		p.pos += end + 4
+		return true
+	}
+	return false
+}
+
 // parseName parses name of the argument or method and returns it.
 func (p *parser) parseName() []byte {
 	pos := p.pos
@@ -819,6 +2354,56 @@ func (p *parser) parseName() []byte {
 	return p.in[pos:p.pos]
 }
 
+// parseDottedName parses a name that may consist of multiple names separated
+// by dots, e.g. "foo.v2", and returns it.
+func (p *parser) parseDottedName() []byte {
+	pos := p.pos
+	if len(p.parseName()) == 0 {
+		return nil
+	}
+	for p.peekByte('.') {
+		dot := p.pos
+		p.read()
+		if len(p.parseName()) == 0 {
+			p.pos = dot
+			break
+		}
+	}
+	return p.in[pos:p.pos]
+}
+
+// peekName returns the name at the current position without advancing
+// the position.
+func (p *parser) peekName() string {
+	pos := p.pos
+	name := string(p.parseName())
+	p.pos = pos
+	return name
+}
+
+// peekTuple returns true if the input at the current position is the
+// "tuple" keyword followed by an opening parenthesis, which may be
+// separated by whitespaces, e.g. "tuple(" or "tuple (".
+func (p *parser) peekTuple() bool {
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	return p.readTuple()
+}
+
+// readTuple works like peekTuple, but if the keyword is found, it advances
+// the position past the opening parenthesis.
+func (p *parser) readTuple() bool {
+	pos := p.pos
+	if string(p.parseName()) == "tuple" {
+		p.parseWhitespace()
+		if p.readByte('(') {
+			return true
+		}
+	}
+	p.pos = pos
+	return false
+}
+
 // parseNumber parses decimal number from the input. The parsed number is
 // returned as integer. If there was no number to parse, the false is returned
 // as second value.
@@ -840,12 +2425,36 @@ func (p *parser) parseNumber() (int, bool, error) {
 	return int(n), true, nil
 }
 
+// peekArray returns true if an array declaration follows. If the
+// WithLenientArrays option is enabled, whitespaces before the opening
+// bracket are skipped.
+func (p *parser) peekArray() bool {
+	if p.peekByte('[') {
+		return true
+	}
+	if !p.opts.lenientArrays {
+		return false
+	}
+	pos := p.pos
+	p.parseWhitespace()
+	if p.peekByte('[') {
+		return true
+	}
+	p.pos = pos
+	return false
+}
+
 // parseArray parses array part of the type declaration. It returns a slice
 // with array dimensions. The -1 value represents an unspecified array size.
 func (p *parser) parseArray() ([]int, error) {
 	var arr []int
 	for p.hasNext() {
-		if p.readByte('[') {
+		if p.peekArray() {
+			p.read()
+			start := p.pos
+			if p.opts.lenientArrays {
+				p.parseWhitespace()
+			}
 			n, ok, err := p.parseNumber()
 			if err != nil {
 				return nil, fmt.Errorf(`invalid array size: %v`, err)
@@ -858,28 +2467,71 @@ func (p *parser) parseArray() ([]int, error) {
 			} else {
 				arr = append(arr, -1)
 			}
+			if p.opts.lenientArrays {
+				p.parseWhitespace()
+			}
 			if !p.hasNext() {
 				return nil, fmt.Errorf(`unexpected end of input, ']' expected`)
 			}
 			if !p.readByte(']') {
+				if size, ok := p.arraySizeAt(start); ok {
+					return nil, fmt.Errorf(`array size must be a number, got %q`, size)
+				}
 				return nil, fmt.Errorf(`unexpected character %q, ']' expected`, p.peek())
 			}
 			continue
 		}
 		break
 	}
+	if len(arr) > 0 && !p.opts.lenientArrays {
+		pos := p.pos
+		p.parseWhitespace()
+		if p.pos > pos && p.peekByte('[') {
+			return nil, fmt.Errorf(`unexpected token after array dimension`)
+		}
+		p.pos = pos
+	}
 	return arr, nil
 }
 
-// onlyWhitespaceOrDelimiterLeft returns true if there are only whitespaces left in the
-// input or if the remaining input is empty.
+// arraySizeAt returns the contents of the array brackets that start at the
+// given position, e.g. "uint256" for "Array[uint256]", without surrounding
+// whitespaces. It returns false if the closing bracket is missing or if the
+// brackets contain characters that cannot be part of a size, like
+// parentheses or commas. It also returns false if the contents are
+// a number, e.g. for "uint256[ 2 ]", because then only the whitespaces
+// are invalid.
+func (p *parser) arraySizeAt(pos int) (string, bool) {
+	for i := pos; i < len(p.in); i++ {
+		switch p.in[i] {
+		case ']':
+			size := strings.TrimSpace(string(p.in[pos:i]))
+			for j := 0; j < len(size); j++ {
+				if !isDigit(size[j]) {
+					return size, true
+				}
+			}
+			return "", false
+		case '[', '(', ')', ',', ';':
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// onlyWhitespaceOrDelimiterLeft returns true if there are only whitespaces,
+// semicolons and, if enabled, comments left in the input or if the remaining
+// input is empty.
 func (p *parser) onlyWhitespaceOrDelimiterLeft() bool {
-	for pos := p.pos; pos < len(p.in); pos++ {
-		if !isWhitespace(p.in[pos]) && p.in[pos] != ';' {
-			return false
+	pos := p.pos
+	defer func() { p.pos = pos }()
+	for {
+		p.parseWhitespace()
+		if !p.readByte(';') {
+			break
 		}
 	}
-	return true
+	return !p.hasNext()
 }
 
 // hasNext returns true if there are more bytes to read.
@@ -952,7 +2604,17 @@ func isAlpha(c byte) bool {
 
 // isWhitespace returns true if b is a whitespace character.
 func isWhitespace(c byte) bool {
-	return c == ' ' || c == '\t' || c == '\n'
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// isParameterKeyword returns true if s is a keyword that may appear between
+// the parameter type and name.
+func isParameterKeyword(s string) bool {
+	switch s {
+	case "indexed", "storage", "memory", "calldata":
+		return true
+	}
+	return false
 }
 
 // isIdentifierSymbol returns true if b is a valid identifier symbol.
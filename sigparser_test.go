@@ -3,6 +3,7 @@ package sigparser
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -10,6 +11,7 @@ func TestParseSignature(t *testing.T) {
 	tests := []struct {
 		kind    SignatureKind
 		sig     string
+		opts    []Option
 		want    Signature
 		wantErr bool
 	}{
@@ -146,6 +148,35 @@ func TestParseSignature(t *testing.T) {
 				Outputs: []Parameter{{Type: "", Tuple: []Parameter{{Type: "uint256", Name: "a"}, {Type: "bool", Name: "b"}}, Name: "c"}},
 			},
 		},
+		{
+			sig: "foo() returns ((uint256 a, uint256 b)[2] c)", // with fixed array of named tuples return value
+			want: Signature{
+				Name: "foo",
+				Outputs: []Parameter{{
+					Name:   "c",
+					Tuple:  []Parameter{{Type: "uint256", Name: "a"}, {Type: "uint256", Name: "b"}},
+					Arrays: []int{2},
+				}},
+			},
+		},
+		{
+			sig: "foo() returns ((uint256 a, (bool x)[] b)[][3] memory c, uint256 d)", // with nested arrays of named tuples
+			want: Signature{
+				Name: "foo",
+				Outputs: []Parameter{
+					{
+						Name: "c",
+						Tuple: []Parameter{
+							{Type: "uint256", Name: "a"},
+							{Name: "b", Tuple: []Parameter{{Type: "bool", Name: "x"}}, Arrays: []int{-1}},
+						},
+						Arrays:       []int{-1, 3},
+						DataLocation: Memory,
+					},
+					{Type: "uint256", Name: "d"},
+				},
+			},
+		},
 		// Alternative tuple syntax
 		{
 			sig: "foo(tuple(uint256,bool))", // with one tuple argument
@@ -154,6 +185,26 @@ func TestParseSignature(t *testing.T) {
 				Inputs: []Parameter{{Type: "", Tuple: []Parameter{{Type: "uint256"}, {Type: "bool"}}}},
 			},
 		},
+		{
+			sig: "foo(tuple (uint256 a) b) returns (tuple\t(bool))", // whitespace after the tuple keyword
+			want: Signature{
+				Name:    "foo",
+				Inputs:  []Parameter{{Name: "b", Tuple: []Parameter{{Type: "uint256", Name: "a"}}}},
+				Outputs: []Parameter{{Tuple: []Parameter{{Type: "bool"}}}},
+			},
+		},
+		{
+			sig:  "foo(struct s)", // struct used as a type name
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "struct", Name: "s"}}},
+		},
+		{
+			sig: "foo(tuple(uint256 a) b) returns (tuple(bool))", // tuple keyword inside parameter lists
+			want: Signature{
+				Name:    "foo",
+				Inputs:  []Parameter{{Name: "b", Tuple: []Parameter{{Type: "uint256", Name: "a"}}}},
+				Outputs: []Parameter{{Tuple: []Parameter{{Type: "bool"}}}},
+			},
+		},
 		// Arrays
 		{
 			sig: "foo(uint256[])", // with one array argument
@@ -233,6 +284,27 @@ func TestParseSignature(t *testing.T) {
 				Inputs: []Parameter{{Type: "uint256"}},
 			},
 		},
+		{
+			sig: "event foo(int indexed a, int indexed b, int indexed c, int indexed d) anonymous",
+			want: Signature{
+				Kind:      EventKind,
+				Name:      "foo",
+				Inputs:    []Parameter{{Type: "int", Name: "a", Indexed: true}, {Type: "int", Name: "b", Indexed: true}, {Type: "int", Name: "c", Indexed: true}, {Type: "int", Name: "d", Indexed: true}},
+				Anonymous: true,
+			},
+		},
+		{
+			sig:  "error Foo()",
+			want: Signature{Kind: ErrorKind, Name: "Foo"},
+		},
+		{
+			sig: "error Foo(bytes a, (bytes b)[] c)",
+			want: Signature{
+				Kind:   ErrorKind,
+				Name:   "Foo",
+				Inputs: []Parameter{{Type: "bytes", Name: "a"}, {Name: "c", Tuple: []Parameter{{Type: "bytes", Name: "b"}}, Arrays: []int{-1}}},
+			},
+		},
 		// With specified kind
 		{
 			kind: EventKind,
@@ -243,6 +315,30 @@ func TestParseSignature(t *testing.T) {
 				Inputs: []Parameter{{Type: "uint256"}},
 			},
 		},
+		{
+			kind: FunctionKind,
+			sig:  "foo",
+			want: Signature{Kind: FunctionKind, Name: "foo"},
+		},
+		{
+			kind: FunctionKind,
+			sig:  "function foo",
+			want: Signature{Kind: FunctionKind, Name: "foo"},
+		},
+		{
+			kind: FunctionKind,
+			sig:  "foo view returns (uint256)",
+			want: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"view"}, ParsedModifiers: []Modifier{{Name: "view"}}, StateMutability: View, Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{kind: FallbackKind, sig: "", want: Signature{Kind: FallbackKind}},
+		{kind: FallbackKind, sig: "fallback", want: Signature{Kind: FallbackKind}},
+		{kind: FallbackKind, sig: "fallback external", want: Signature{Kind: FallbackKind, Modifiers: []string{"external"}, ParsedModifiers: []Modifier{{Name: "external"}}, Visibility: External}},
+		{kind: ReceiveKind, sig: "", want: Signature{Kind: ReceiveKind}},
+		{kind: ReceiveKind, sig: "receive", want: Signature{Kind: ReceiveKind}},
+		{kind: ReceiveKind, sig: "receive external payable", want: Signature{Kind: ReceiveKind, Modifiers: []string{"external", "payable"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}}, StateMutability: Payable, Visibility: External}},
+		{kind: FallbackKind, sig: "fallback foo", wantErr: true},
+		{kind: ReceiveKind, sig: "receive foo", wantErr: true},
+		{kind: EventKind, sig: "Foo", wantErr: true},
 		// Data location
 		{
 			sig: "foo(int memory a, int storage, int calldata)",
@@ -258,20 +354,73 @@ func TestParseSignature(t *testing.T) {
 				Outputs: []Parameter{{Type: "int", Name: "a", DataLocation: Memory}, {Type: "int", DataLocation: Storage}, {Type: "int", DataLocation: CallData}},
 			},
 		},
+		{
+			sig: "foo((uint256,uint256) memory a) returns ((bool b)[] calldata)",
+			want: Signature{
+				Name:    "foo",
+				Inputs:  []Parameter{{Name: "a", Tuple: []Parameter{{Type: "uint256"}, {Type: "uint256"}}, DataLocation: Memory}},
+				Outputs: []Parameter{{Tuple: []Parameter{{Type: "bool", Name: "b"}}, Arrays: []int{-1}, DataLocation: CallData}},
+			},
+		},
+		{
+			sig: "foo() returns (tuple(uint256 a, uint256 b) result)",
+			want: Signature{
+				Name:    "foo",
+				Outputs: []Parameter{{Name: "result", Tuple: []Parameter{{Type: "uint256", Name: "a"}, {Type: "uint256", Name: "b"}}}},
+			},
+		},
+		{
+			sig: "foo() view returns (tuple(uint256 a)[] memory result, uint256 c)",
+			want: Signature{
+				Name:            "foo",
+				Modifiers:       []string{"view"},
+				ParsedModifiers: []Modifier{{Name: "view"}},
+				StateMutability: View,
+				Outputs:         []Parameter{{Name: "result", Tuple: []Parameter{{Type: "uint256", Name: "a"}}, Arrays: []int{-1}, DataLocation: Memory}, {Type: "uint256", Name: "c"}},
+			},
+		},
 		// Modifiers
 		{
 			sig: "foo() view pure",
 			want: Signature{
-				Name:      "foo",
-				Modifiers: []string{"view", "pure"},
+				Name:            "foo",
+				Modifiers:       []string{"view", "pure"},
+				ParsedModifiers: []Modifier{{Name: "view"}, {Name: "pure"}},
+				StateMutability: View,
 			},
 		},
 		{
 			sig: "foo() view pure returns (int)",
 			want: Signature{
-				Name:      "foo",
-				Modifiers: []string{"view", "pure"},
-				Outputs:   []Parameter{{Type: "int"}},
+				Name:            "foo",
+				Modifiers:       []string{"view", "pure"},
+				ParsedModifiers: []Modifier{{Name: "view"}, {Name: "pure"}},
+				StateMutability: View,
+				Outputs:         []Parameter{{Type: "int"}},
+			},
+		},
+		{
+			sig: "balanceOf(address) external view returns (uint256)",
+			want: Signature{
+				Name:            "balanceOf",
+				Inputs:          []Parameter{{Type: "address"}},
+				Outputs:         []Parameter{{Type: "uint256"}},
+				Modifiers:       []string{"external", "view"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+				Visibility:      External,
+				StateMutability: View,
+			},
+		},
+		{
+			sig: "function totalSupply() public view returns (uint256)",
+			want: Signature{
+				Kind:            FunctionKind,
+				Name:            "totalSupply",
+				Outputs:         []Parameter{{Type: "uint256"}},
+				Modifiers:       []string{"public", "view"},
+				ParsedModifiers: []Modifier{{Name: "public"}, {Name: "view"}},
+				Visibility:      Public,
+				StateMutability: View,
 			},
 		},
 		{
@@ -279,21 +428,84 @@ func TestParseSignature(t *testing.T) {
 			want: Signature{
 				Kind:      EventKind,
 				Name:      "foo",
-				Modifiers: []string{"anonymous"},
+				Anonymous: true,
 				Inputs:    []Parameter{{Type: "int", Name: "a"}},
 			},
 		},
+		{
+			sig: "Foo(uint256 indexed a) anonymous",
+			want: Signature{
+				Name:      "Foo",
+				Anonymous: true,
+				Inputs:    []Parameter{{Type: "uint256", Name: "a", Indexed: true}},
+			},
+		},
 		//
 		// Allowed arguments for fallback function
 		{
 			sig: "fallback (bytes calldata _input) external returns (bytes memory _output)",
 			want: Signature{
-				Kind:      FallbackKind,
-				Inputs:    []Parameter{{Type: "bytes", Name: "_input", DataLocation: CallData}},
-				Outputs:   []Parameter{{Type: "bytes", Name: "_output", DataLocation: Memory}},
-				Modifiers: []string{"external"},
+				Kind:            FallbackKind,
+				Inputs:          []Parameter{{Type: "bytes", Name: "_input", DataLocation: CallData}},
+				Outputs:         []Parameter{{Type: "bytes", Name: "_output", DataLocation: Memory}},
+				Modifiers:       []string{"external"},
+				ParsedModifiers: []Modifier{{Name: "external"}},
+				Visibility:      External,
 			},
 		},
+		// Payable
+		{
+			sig:  "function foo() external payable",
+			want: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"external", "payable"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}}, StateMutability: Payable, Visibility: External},
+		},
+		{
+			sig:  "constructor(uint256 a) payable",
+			want: Signature{Kind: ConstructorKind, Inputs: []Parameter{{Type: "uint256", Name: "a"}}, Modifiers: []string{"payable"}, ParsedModifiers: []Modifier{{Name: "payable"}}, StateMutability: Payable},
+		},
+		{
+			sig:  "fallback() external payable",
+			want: Signature{Kind: FallbackKind, Modifiers: []string{"external", "payable"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}}, StateMutability: Payable, Visibility: External},
+		},
+		{
+			sig:  "receive() external payable",
+			want: Signature{Kind: ReceiveKind, Modifiers: []string{"external", "payable"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}}, StateMutability: Payable, Visibility: External},
+		},
+		// Version profile
+		{
+			sig:  "constructor(uint256 a) public payable",
+			opts: []Option{WithVersionProfile(LegacySolidity)},
+			want: Signature{Kind: ConstructorKind, Inputs: []Parameter{{Type: "uint256", Name: "a"}}, Modifiers: []string{"public", "payable"}, ParsedModifiers: []Modifier{{Name: "public"}, {Name: "payable"}}, StateMutability: Payable, Visibility: Public},
+		},
+		{
+			sig:  "constructor() internal",
+			opts: []Option{WithVersionProfile(LegacySolidity)},
+			want: Signature{Kind: ConstructorKind, Modifiers: []string{"internal"}, ParsedModifiers: []Modifier{{Name: "internal"}}, Visibility: Internal},
+		},
+		{sig: "constructor() public", opts: []Option{WithVersionProfile(ModernSolidity)}, wantErr: true},
+		{sig: "constructor() private", opts: []Option{WithVersionProfile(LegacySolidity)}, wantErr: true},
+		{sig: "constructor() view", opts: []Option{WithVersionProfile(LegacySolidity)}, wantErr: true},
+		// Strict fallback
+		{
+			sig:  "fallback(bytes calldata) external returns (bytes memory)",
+			opts: []Option{WithStrictFallback()},
+			want: Signature{
+				Kind:            FallbackKind,
+				Inputs:          []Parameter{{Type: "bytes", DataLocation: CallData}},
+				Outputs:         []Parameter{{Type: "bytes", DataLocation: Memory}},
+				Modifiers:       []string{"external"},
+				ParsedModifiers: []Modifier{{Name: "external"}},
+				Visibility:      External,
+			},
+		},
+		{
+			sig:  "fallback() external",
+			opts: []Option{WithStrictFallback()},
+			want: Signature{Kind: FallbackKind, Modifiers: []string{"external"}, ParsedModifiers: []Modifier{{Name: "external"}}, Visibility: External},
+		},
+		{
+			sig:  "fallback(bytes memory) returns (bytes calldata)",
+			want: Signature{Kind: FallbackKind, Inputs: []Parameter{{Type: "bytes", DataLocation: Memory}}, Outputs: []Parameter{{Type: "bytes", DataLocation: CallData}}},
+		},
 		// Different formatting
 		{
 			sig: "foo(t1 n1,(t2 n2,t3 n3))(t4 n4,(t5 n5,t6 n6))",
@@ -320,6 +532,22 @@ func TestParseSignature(t *testing.T) {
 				Outputs: []Parameter{{Type: "t4", Name: "n4"}, {Type: "", Tuple: []Parameter{{Type: "t5", Name: "n5"}, {Type: "t6", Name: "n6"}}}},
 			},
 		},
+		{
+			sig: "foo(\r\n  uint256 a\r\n)",
+			want: Signature{
+				Name:   "foo",
+				Inputs: []Parameter{{Type: "uint256", Name: "a"}},
+			},
+		},
+		{
+			sig: "function\r\nfoo(\r\n\tuint256 a,\r\n\tuint256 b\r\n)\r\nreturns\v(\fuint256\f)\r\n",
+			want: Signature{
+				Kind:    FunctionKind,
+				Name:    "foo",
+				Inputs:  []Parameter{{Type: "uint256", Name: "a"}, {Type: "uint256", Name: "b"}},
+				Outputs: []Parameter{{Type: "uint256"}},
+			},
+		},
 		// Nested tuples and arrays
 		{
 			sig: "function foo(((int[][1][2] a,int[][1][2] b)[][1][2],(int[][1][2] a,int[][1][2] b)[][1][2])[][1][2])",
@@ -351,28 +579,363 @@ func TestParseSignature(t *testing.T) {
 		},
 
 		// Signatures with a valid syntax but invalid semantics
-		{sig: "function foo(int indexed a)", wantErr: true},     // indexed flag not allowed for non-events
-		{sig: "foo()(int indexed a)", wantErr: true},            // indexed flag not allowed for output values
-		{sig: "foo()[1]", wantErr: true},                        // input tuples cannot be arrays
-		{sig: "foo()(int)[1]", wantErr: true},                   // output tuples cannot be arrays
-		{sig: "constructor foo()", wantErr: true},               // constructors cannot have a name
-		{sig: "constructor() internal", wantErr: true},          // constructors cannot have modifiers
-		{sig: "constructor() returns (uint256)", wantErr: true}, // constructors cannot have return values
-		{sig: "fallback foo()", wantErr: true},                  // fallbacks cannot have a name
-		{sig: "fallback(uint256)", wantErr: true},               // fallbacks cannot have arguments other that bytes
-		{sig: "fallback() returns (uint256)", wantErr: true},    // fallbacks cannot have return values other that bytes
-		{sig: "fallback(bytes indexed a) ", wantErr: true},      // indexed flag not allowed for non-events
-		{sig: "receive foo()", wantErr: true},                   // receives cannot have a name
-		{sig: "receive(uint256)", wantErr: true},                // receives cannot have arguments
-		{sig: "receive() returns (uint256)", wantErr: true},     // receives cannot have return values
-		{sig: "event foo()", wantErr: true},                     // events must have arguments
-		{sig: "event foo(int) internal", wantErr: true},         // events cannot have modifiers
-		{sig: "event foo(int) returns (int)", wantErr: true},    // events cannot have return values
-		{sig: "event foo(int memory a)", wantErr: true},         // event arguments cannot specify data location
-		{sig: "error foo(int) internal", wantErr: true},         // errors cannot have modifiers other than anonymous
-		{sig: "error foo() returns (int)", wantErr: true},       // errors cannot have return values
-		{sig: "error foo(int memory a)", wantErr: true},         // error arguments cannot specify data location
-		{sig: "error foo(int indexed a)", wantErr: true},        // indexed flag not allowed for non-events
+		{sig: "function foo(int indexed a)", wantErr: true},                                                          // indexed flag not allowed for non-events
+		{sig: "foo()(int indexed a)", wantErr: true},                                                                 // indexed flag not allowed for output values
+		{sig: "foo()[1]", wantErr: true},                                                                             // input tuples cannot be arrays
+		{sig: "foo()(int)[1]", wantErr: true},                                                                        // output tuples cannot be arrays
+		{sig: "constructor foo()", wantErr: true},                                                                    // constructors cannot have a name
+		{sig: "constructor() internal", wantErr: true},                                                               // constructors cannot have modifiers
+		{sig: "constructor() returns (uint256)", wantErr: true},                                                      // constructors cannot have return values
+		{sig: "fallback foo()", wantErr: true},                                                                       // fallbacks cannot have a name
+		{sig: "fallback(uint256)", wantErr: true},                                                                    // fallbacks cannot have arguments other that bytes
+		{sig: "fallback() returns (uint256)", wantErr: true},                                                         // fallbacks cannot have return values other that bytes
+		{sig: "fallback(bytes indexed a) ", wantErr: true},                                                           // indexed flag not allowed for non-events
+		{sig: "receive foo()", wantErr: true},                                                                        // receives cannot have a name
+		{sig: "receive(uint256)", wantErr: true},                                                                     // receives cannot have arguments
+		{sig: "receive() returns (uint256)", wantErr: true},                                                          // receives cannot have return values
+		{sig: "event foo()", wantErr: true},                                                                          // events must have arguments
+		{sig: "event foo(int) internal", wantErr: true},                                                              // events cannot have modifiers
+		{sig: "event foo(int) returns (int)", wantErr: true},                                                         // events cannot have return values
+		{sig: "event foo(int memory a)", wantErr: true},                                                              // event arguments cannot specify data location
+		{sig: "event foo(int indexed, int indexed, int indexed, int indexed)", wantErr: true},                        // at most 3 indexed arguments
+		{sig: "event foo(int indexed, int indexed, int indexed, int indexed, int indexed) anonymous", wantErr: true}, // at most 4 indexed arguments for anonymous events
+		{sig: "error foo(int) internal", wantErr: true},                                                              // errors cannot have modifiers other than anonymous
+		{sig: "error foo() returns (int)", wantErr: true},                                                            // errors cannot have return values
+		{sig: "error foo(int memory a)", wantErr: true},                                                              // error arguments cannot specify data location
+		{sig: "error foo(int indexed a)", wantErr: true},                                                             // indexed flag not allowed for non-events
+		// Modifiers after return values
+		{sig: "foo() returns (uint256) view", wantErr: true},
+		{sig: "foo()(uint256) view", wantErr: true},
+		{
+			sig:  "foo() external returns (uint256) view",
+			opts: []Option{WithModifiersAfterReturns()},
+			want: Signature{
+				Name:            "foo",
+				Outputs:         []Parameter{{Type: "uint256"}},
+				Modifiers:       []string{"external", "view"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+				Visibility:      External,
+				StateMutability: View,
+			},
+		},
+		{
+			sig:  "foo()(uint256) pure",
+			opts: []Option{WithModifiersAfterReturns()},
+			want: Signature{
+				Name:            "foo",
+				Outputs:         []Parameter{{Type: "uint256"}},
+				Modifiers:       []string{"pure"},
+				ParsedModifiers: []Modifier{{Name: "pure"}},
+				StateMutability: Pure,
+			},
+		},
+		// Dotted names
+		{sig: "foo.v2(uint256)", wantErr: true},
+		{
+			sig:  "foo.v2(uint256)",
+			opts: []Option{WithDottedNames()},
+			want: Signature{Name: "foo.v2", Inputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "event foo.bar.v2(uint256 a)",
+			opts: []Option{WithDottedNames()},
+			want: Signature{Kind: EventKind, Name: "foo.bar.v2", Inputs: []Parameter{{Type: "uint256", Name: "a"}}},
+		},
+		{sig: "foo.v2(a.b)", opts: []Option{WithDottedNames()}, wantErr: true},
+		{sig: "foo.(uint256)", opts: []Option{WithDottedNames()}, wantErr: true},
+		{sig: "foo..v2(uint256)", opts: []Option{WithDottedNames()}, wantErr: true},
+		// Scope
+		{sig: "IERC20.transfer(address to, uint256 amount)", wantErr: true},
+		{
+			sig:  "IERC20.transfer(address to, uint256 amount)",
+			opts: []Option{WithScopedNames()},
+			want: Signature{Scope: "IERC20", Name: "transfer", Inputs: []Parameter{{Type: "address", Name: "to"}, {Type: "uint256", Name: "amount"}}},
+		},
+		{
+			sig:  "function IERC20.balanceOf(address) view returns (uint256)",
+			opts: []Option{WithScopedNames()},
+			want: Signature{Kind: FunctionKind, Scope: "IERC20", Name: "balanceOf", Inputs: []Parameter{{Type: "address"}}, Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"view"}, ParsedModifiers: []Modifier{{Name: "view"}}, StateMutability: View},
+		},
+		{
+			sig:  "event IERC20.Transfer(address indexed from)",
+			opts: []Option{WithScopedNames()},
+			want: Signature{Kind: EventKind, Scope: "IERC20", Name: "Transfer", Inputs: []Parameter{{Type: "address", Name: "from", Indexed: true}}},
+		},
+		{
+			sig:  "foo.v2(uint256)",
+			opts: []Option{WithScopedNames(), WithDottedNames()},
+			want: Signature{Name: "foo.v2", Inputs: []Parameter{{Type: "uint256"}}},
+		},
+		{sig: "IERC20.(uint256)", opts: []Option{WithScopedNames()}, wantErr: true},
+		{sig: ".transfer(uint256)", opts: []Option{WithScopedNames()}, wantErr: true},
+		{sig: "constructor A.b()", opts: []Option{WithScopedNames()}, wantErr: true},
+		// Override with base contracts
+		{
+			sig:  "foo() public view virtual override returns (uint256)",
+			want: Signature{Name: "foo", Modifiers: []string{"public", "view", "virtual", "override"}, ParsedModifiers: []Modifier{{Name: "public"}, {Name: "view"}, {Name: "virtual"}, {Name: "override"}}, StateMutability: View, Visibility: Public, Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo() external override(A,IB.C) view returns (uint256)",
+			want: Signature{Name: "foo", Modifiers: []string{"external", "override", "view"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "override", Args: []string{"A", "IB.C"}}, {Name: "view"}}, StateMutability: View, Visibility: External, Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo() override( A ) returns (uint256)",
+			want: Signature{Name: "foo", Modifiers: []string{"override"}, ParsedModifiers: []Modifier{{Name: "override", Args: []string{"A"}}}, Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo() override (uint256)",
+			want: Signature{Name: "foo", Modifiers: []string{"override"}, ParsedModifiers: []Modifier{{Name: "override"}}, Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo() override(uint256)",
+			want: Signature{Name: "foo", Modifiers: []string{"override"}, ParsedModifiers: []Modifier{{Name: "override"}}, Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo() view override(address, bool)",
+			want: Signature{Name: "foo", Modifiers: []string{"view", "override"}, ParsedModifiers: []Modifier{{Name: "view"}, {Name: "override"}}, StateMutability: View, Outputs: []Parameter{{Type: "address"}, {Type: "bool"}}},
+		},
+		{
+			sig:  "foo() override(uint256 a)",
+			want: Signature{Name: "foo", Modifiers: []string{"override"}, ParsedModifiers: []Modifier{{Name: "override"}}, Outputs: []Parameter{{Type: "uint256", Name: "a"}}},
+		},
+		{
+			sig:  "function foo() public override(A, B)",
+			want: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"public", "override"}, ParsedModifiers: []Modifier{{Name: "public"}, {Name: "override", Args: []string{"A", "B"}}}, Visibility: Public},
+		},
+		{sig: "foo() override(A,) returns (uint256)", wantErr: true},
+		// Ethers compatibility
+		{sig: "event Paused()", opts: []Option{WithEthersCompat()}, want: Signature{Kind: EventKind, Name: "Paused"}},
+		{
+			sig:  "function foo(uint256 a) external view returns (uint256) @29000",
+			opts: []Option{WithEthersCompat()},
+			want: Signature{Kind: FunctionKind, Name: "foo", Inputs: []Parameter{{Type: "uint256", Name: "a"}}, Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"external", "view"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}}, StateMutability: View, Visibility: External},
+		},
+		{
+			sig:  "function foo(tuple(uint256 a, address payable b) [ ] x) payable@100",
+			opts: []Option{WithEthersCompat()},
+			want: Signature{Kind: FunctionKind, Name: "foo", Inputs: []Parameter{{Name: "x", Tuple: []Parameter{{Type: "uint256", Name: "a"}, {Type: "address", Name: "b", Payable: true}}, Arrays: []int{-1}}}, Modifiers: []string{"payable"}, ParsedModifiers: []Modifier{{Name: "payable"}}, StateMutability: Payable},
+		},
+		{sig: "function foo() @29000", wantErr: true},
+		{sig: "function foo() @", opts: []Option{WithEthersCompat()}, wantErr: true},
+		{sig: "function foo() @gas", opts: []Option{WithEthersCompat()}, wantErr: true},
+		// Tuple field names
+		{
+			sig:  "foo(uint256, (uint256 a, bool b)) returns (bool)",
+			opts: []Option{WithRequireTupleFieldNames()},
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "uint256"}, {Tuple: []Parameter{{Type: "uint256", Name: "a"}, {Type: "bool", Name: "b"}}}}, Outputs: []Parameter{{Type: "bool"}}},
+		},
+		// Modifiers
+		{sig: "modifier onlyOwner", want: Signature{Kind: ModifierKind, Name: "onlyOwner"}},
+		{sig: "modifier onlyOwner()", want: Signature{Kind: ModifierKind, Name: "onlyOwner"}},
+		{
+			sig:  "modifier onlyRole(bytes32 role, address[] memory who) virtual override(A, B)",
+			want: Signature{Kind: ModifierKind, Name: "onlyRole", Inputs: []Parameter{{Type: "bytes32", Name: "role"}, {Type: "address", Name: "who", Arrays: []int{-1}, DataLocation: Memory}}, Modifiers: []string{"virtual", "override"}, ParsedModifiers: []Modifier{{Name: "virtual"}, {Name: "override", Args: []string{"A", "B"}}}},
+		},
+		{sig: "modifier()", wantErr: true},
+		{sig: "modifier foo() view", wantErr: true},
+		{sig: "modifier foo() returns (uint256)", wantErr: true},
+		{sig: "modifier foo(uint256 indexed a)", wantErr: true},
+		// Modifier invocations
+		{
+			sig:  "function grant(bytes32 role) external onlyRole(DEFAULT_ADMIN_ROLE) returns (bool)",
+			want: Signature{Kind: FunctionKind, Name: "grant", Inputs: []Parameter{{Type: "bytes32", Name: "role"}}, Outputs: []Parameter{{Type: "bool"}}, Modifiers: []string{"external", "onlyRole"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "onlyRole", Args: []string{"DEFAULT_ADMIN_ROLE"}}}, Visibility: External},
+		},
+		{
+			sig:  "foo() onlyOwner() when( isOpen(x, \"a,)\") ,[1, 2] ) nonReentrant",
+			want: Signature{Name: "foo", Modifiers: []string{"onlyOwner", "when", "nonReentrant"}, ParsedModifiers: []Modifier{{Name: "onlyOwner", Args: []string{}}, {Name: "when", Args: []string{`isOpen(x, "a,)")`, "[1, 2]"}}, {Name: "nonReentrant"}}},
+		},
+		{
+			sig:  "foo() onlyOwner (uint256)",
+			want: Signature{Name: "foo", Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"onlyOwner"}, ParsedModifiers: []Modifier{{Name: "onlyOwner"}}},
+		},
+		{
+			sig:  "foo() view(uint256)",
+			want: Signature{Name: "foo", Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"view"}, ParsedModifiers: []Modifier{{Name: "view"}}, StateMutability: View},
+		},
+		{
+			sig:  "foo() nonReentrant(uint256)",
+			want: Signature{Name: "foo", Outputs: []Parameter{{Type: "uint256"}}, Modifiers: []string{"nonReentrant"}, ParsedModifiers: []Modifier{{Name: "nonReentrant"}}},
+		},
+		{
+			sig:  "foo() nonReentrant(uint256[] memory a, bool)",
+			want: Signature{Name: "foo", Outputs: []Parameter{{Type: "uint256", Name: "a", Arrays: []int{-1}, DataLocation: Memory}, {Type: "bool"}}, Modifiers: []string{"nonReentrant"}, ParsedModifiers: []Modifier{{Name: "nonReentrant"}}},
+		},
+		{
+			sig:  "foo() onlyRole(bytes32(0))",
+			want: Signature{Name: "foo", Modifiers: []string{"onlyRole"}, ParsedModifiers: []Modifier{{Name: "onlyRole", Args: []string{"bytes32(0)"}}}},
+		},
+		{sig: "foo() onlyRole(ADMIN", wantErr: true},
+		{
+			sig: "foo() view event",
+			want: Signature{
+				Name:            "foo",
+				Modifiers:       []string{"view", "event"},
+				ParsedModifiers: []Modifier{{Name: "view"}, {Name: "event"}},
+				StateMutability: View,
+			},
+		},
+		// Function types
+		{
+			sig: "function foo(function(uint256) external returns (bool) callback, uint256 x)",
+			want: Signature{
+				Kind: FunctionKind,
+				Name: "foo",
+				Inputs: []Parameter{
+					{Type: "function", Name: "callback", Function: &FunctionType{Inputs: []Parameter{{Type: "uint256"}}, Outputs: []Parameter{{Type: "bool"}}, Visibility: External}},
+					{Type: "uint256", Name: "x"},
+				},
+			},
+		},
+		{
+			sig: "foo(function (uint256 a, (bool, string) b)external view returns(bool)[] memory cbs) returns (function() f)",
+			want: Signature{
+				Name: "foo",
+				Inputs: []Parameter{{
+					Type:         "function",
+					Name:         "cbs",
+					Arrays:       []int{-1},
+					DataLocation: Memory,
+					Function: &FunctionType{
+						Inputs:          []Parameter{{Type: "uint256", Name: "a"}, {Name: "b", Tuple: []Parameter{{Type: "bool"}, {Type: "string"}}}},
+						Outputs:         []Parameter{{Type: "bool"}},
+						Visibility:      External,
+						StateMutability: View,
+					},
+				}},
+				Outputs: []Parameter{{Type: "function", Name: "f", Function: &FunctionType{}}},
+			},
+		},
+		{
+			sig:  "foo(function(uint)[2] fns, function() internal pure)",
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "function", Name: "fns", Arrays: []int{2}, Function: &FunctionType{Inputs: []Parameter{{Type: "uint"}}}}, {Type: "function", Function: &FunctionType{Visibility: Internal, StateMutability: Pure}}}},
+		},
+		{
+			sig:  "foo(function(function() external payable) external)",
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "function", Function: &FunctionType{Inputs: []Parameter{{Type: "function", Function: &FunctionType{Visibility: External, StateMutability: Payable}}}, Visibility: External}}}},
+		},
+		{sig: "foo(function() public)", wantErr: true},
+		{sig: "foo(function() external internal)", wantErr: true},
+		{sig: "foo(function() view pure)", wantErr: true},
+		{sig: "foo(function() returns uint256)", wantErr: true},
+		{sig: "foo(function( x)", wantErr: true},
+		// Keywords as a part of names
+		{sig: "errors()", want: Signature{Name: "errors"}},
+		{sig: "eventCount()", want: Signature{Name: "eventCount"}},
+		{sig: "modifiers(uint256)", want: Signature{Name: "modifiers", Inputs: []Parameter{{Type: "uint256"}}}},
+		{sig: "function functionality()", want: Signature{Kind: FunctionKind, Name: "functionality"}},
+		// Returns keyword
+		{sig: "foo()(uint256)", opts: []Option{WithRequireReturnsKeyword()}, wantErr: true},
+		{sig: "foo() view (uint256)", opts: []Option{WithRequireReturnsKeyword()}, wantErr: true},
+		{
+			sig:  "foo() returns (uint256)",
+			opts: []Option{WithRequireReturnsKeyword()},
+			want: Signature{Name: "foo", Outputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo(uint256)",
+			opts: []Option{WithRequireReturnsKeyword()},
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "uint256"}}},
+		},
+		// Strict modifiers
+		{
+			sig:  "function foo() external view virtual override(A) onlyOwner onlyOwner returns (uint256)",
+			opts: []Option{WithStrictModifiers()},
+			want: Signature{
+				Kind:            FunctionKind,
+				Name:            "foo",
+				Outputs:         []Parameter{{Type: "uint256"}},
+				Modifiers:       []string{"external", "view", "virtual", "override", "onlyOwner", "onlyOwner"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}, {Name: "virtual"}, {Name: "override", Args: []string{"A"}}, {Name: "onlyOwner"}, {Name: "onlyOwner"}},
+				StateMutability: View,
+				Visibility:      External,
+			},
+		},
+		{
+			sig:  "foo() view pure",
+			want: Signature{Name: "foo", Modifiers: []string{"view", "pure"}, ParsedModifiers: []Modifier{{Name: "view"}, {Name: "pure"}}, StateMutability: View},
+		},
+		{sig: "foo() view pure", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "foo() payable view", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "foo() constant view", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "foo() virtual virtual", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "foo() override override(A)", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "foo() private virtual", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{
+			sig:  "receive() external payable virtual",
+			opts: []Option{WithStrictModifiers()},
+			want: Signature{Kind: ReceiveKind, Modifiers: []string{"external", "payable", "virtual"}, ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}, {Name: "virtual"}}, StateMutability: Payable, Visibility: External},
+		},
+		{
+			sig:  "fallback(bytes calldata input) external payable returns (bytes memory output)",
+			opts: []Option{WithStrictModifiers()},
+			want: Signature{
+				Kind:            FallbackKind,
+				Inputs:          []Parameter{{Type: "bytes", Name: "input", DataLocation: CallData}},
+				Outputs:         []Parameter{{Type: "bytes", Name: "output", DataLocation: Memory}},
+				Modifiers:       []string{"external", "payable"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "payable"}},
+				StateMutability: Payable,
+				Visibility:      External,
+			},
+		},
+		{
+			sig:  "fallback() external",
+			opts: []Option{WithStrictModifiers()},
+			want: Signature{Kind: FallbackKind, Modifiers: []string{"external"}, ParsedModifiers: []Modifier{{Name: "external"}}, Visibility: External},
+		},
+		{sig: "receive()", want: Signature{Kind: ReceiveKind}},
+		{sig: "receive()", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "receive() external", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "receive() public payable", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "fallback() payable", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "fallback() external view", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		// Strict event syntax
+		{
+			sig:  "event Foo(uint256 indexed a, uint256 b, (uint256 c) d, uint256)",
+			opts: []Option{WithStrictEventSyntax()},
+			want: Signature{
+				Kind:   EventKind,
+				Name:   "Foo",
+				Inputs: []Parameter{{Type: "uint256", Name: "a", Indexed: true}, {Type: "uint256", Name: "b"}, {Name: "d", Tuple: []Parameter{{Type: "uint256", Name: "c"}}}, {Type: "uint256"}},
+			},
+		},
+		{
+			sig:  "foo(uint256 memory a)",
+			opts: []Option{WithStrictEventSyntax()},
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "uint256", Name: "a", DataLocation: Memory}}},
+		},
+		{sig: "event Foo(uint256 a indexed)", opts: []Option{WithStrictEventSyntax()}, wantErr: true},
+		{sig: "event Foo(uint256 indexed indexed)", opts: []Option{WithStrictEventSyntax()}, wantErr: true},
+		{sig: "event Foo(uint256 indexed memory)", opts: []Option{WithStrictEventSyntax()}, wantErr: true},
+		{sig: "event Foo(uint256 memory indexed a)", opts: []Option{WithStrictEventSyntax()}, wantErr: true},
+		{
+			sig:  "event Foo(uint256 indexed memory)",
+			want: Signature{Kind: EventKind, Name: "Foo", Inputs: []Parameter{{Type: "uint256", Name: "memory", Indexed: true}}},
+		},
+		// Comments
+		{sig: "foo(uint256); // transfer", wantErr: true},
+		{
+			sig:  "foo(uint256); // transfer",
+			opts: []Option{WithComments()},
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "foo(uint256) // transfer\n",
+			opts: []Option{WithComments()},
+			want: Signature{Name: "foo", Inputs: []Parameter{{Type: "uint256"}}},
+		},
+		{
+			sig:  "/* doc */ function foo(\n\tuint256 a, // amount\n\taddress /* recipient */ b\n) returns (bool); /* end */",
+			opts: []Option{WithComments()},
+			want: Signature{
+				Kind:    FunctionKind,
+				Name:    "foo",
+				Inputs:  []Parameter{{Type: "uint256", Name: "a"}, {Type: "address", Name: "b"}},
+				Outputs: []Parameter{{Type: "bool"}},
+			},
+		},
+		{sig: "foo(uint256); /* unterminated", opts: []Option{WithComments()}, wantErr: true},
+		{sig: "foo(uint256); / not a comment", opts: []Option{WithComments()}, wantErr: true},
 		// Invalid syntax
 		{sig: "foo()()a", wantErr: true},
 		{sig: "foo()returns[]", wantErr: true},
@@ -391,10 +954,16 @@ func TestParseSignature(t *testing.T) {
 		{sig: "A( ", wantErr: true},
 		{sig: "(A[", wantErr: true},
 		{sig: "A()returns", wantErr: true},
+		{sig: "function foo() public external", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{sig: "function foo() internal internal", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{
+			sig:  "function foo() public external",
+			want: Signature{Kind: FunctionKind, Name: "foo", Modifiers: []string{"public", "external"}, ParsedModifiers: []Modifier{{Name: "public"}, {Name: "external"}}, Visibility: Public},
+		},
 	}
 	for n, tt := range tests {
 		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
-			got, err := ParseSignatureAs(tt.kind, tt.sig)
+			got, err := ParseSignatureAs(tt.kind, tt.sig, tt.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseSignatureAs() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -406,9 +975,207 @@ func TestParseSignature(t *testing.T) {
 	}
 }
 
+func TestParseSignatureErrorMessages(t *testing.T) {
+	tests := []struct {
+		sig  string
+		opts []Option
+		want string
+	}{
+		{sig: "event Foo(uint256) view", want: `modifier "view" not allowed on event`},
+		{sig: "event Foo(uint256) pure", want: `modifier "pure" not allowed on event`},
+		{sig: "event Foo(uint256) constant", want: `modifier "constant" not allowed on event`},
+		{sig: "event Foo(uint256) anonymous view", want: `modifier "view" not allowed on event`},
+		{sig: "event Foo(uint256) anonymous anonymous", want: `duplicate event modifier "anonymous"`},
+		{sig: "foo()(uint256)", opts: []Option{WithRequireReturnsKeyword()}, want: `outputs must be introduced with the 'returns' keyword`},
+		{sig: "error Foo(bytes calldata a)", want: `unexpected data location "calldata" in error input`},
+		{sig: "error Foo(bytes memory a)", want: `unexpected data location "memory" in error input`},
+		{sig: "error Foo(uint256 a, (bytes storage b) c)", want: `unexpected data location "storage" in error input`},
+		{sig: "error Foo(((bytes calldata b)[] c) d)", want: `unexpected data location "calldata" in error input`},
+		{sig: "event Foo((bytes memory b) c)", want: `unexpected data location "memory" in event input`},
+		{sig: "event Foo(uint256 a indexed)", opts: []Option{WithStrictEventSyntax()}, want: `unexpected "indexed" after parameter name "a", event parameters must follow the "type [indexed] [name]" order`},
+		{sig: "event Foo(uint256 indexed memory)", opts: []Option{WithStrictEventSyntax()}, want: `unexpected keyword "memory", event parameters must follow the "type [indexed] [name]" order`},
+		{sig: "foo tuple(uint256)", want: `the 'tuple' keyword cannot be used for the input parameter list, use '(' instead`},
+		{sig: "function foo tuple(uint256 a)", want: `the 'tuple' keyword cannot be used for the input parameter list, use '(' instead`},
+		{sig: "foo tuple (uint256)", want: `the 'tuple' keyword cannot be used for the input parameter list, use '(' instead`},
+		{sig: "foo() returns tuple(uint256)", want: `the 'tuple' keyword cannot be used for the output parameter list, use '(' instead`},
+		{sig: "foo() view tuple(uint256)", want: `the 'tuple' keyword cannot be used for the output parameter list, use '(' instead`},
+		{sig: "foo() view tuple (uint256)", want: `the 'tuple' keyword cannot be used for the output parameter list, use '(' instead`},
+		{sig: "foo() returns (struct { uint256 a; } s)", want: `inline struct definitions are not valid in signatures; use a tuple`},
+		{sig: "foo(struct S { uint256 a; } s)", want: `inline struct definitions are not valid in signatures; use a tuple`},
+		{sig: "foo(uint256, struct{uint256 a;})", want: `inline struct definitions are not valid in signatures; use a tuple`},
+		{sig: "event Foo(uint256) payable", want: `modifier "payable" not allowed on event`},
+		{sig: "error Foo(uint256) payable", want: `modifier "payable" not allowed on error`},
+		{sig: "error Foo(uint256) view", want: `modifier "view" not allowed on error`},
+		{sig: "constructor() view", want: `modifier "view" not allowed on constructor`},
+		{sig: "constructor() public", want: `constructor visibility "public" is only allowed before Solidity 0.7.0, see the LegacySolidity profile`},
+		{sig: "constructor() external", opts: []Option{WithVersionProfile(LegacySolidity)}, want: `modifier "external" not allowed on constructor`},
+		{sig: "constructor() public internal", opts: []Option{WithVersionProfile(LegacySolidity), WithStrictModifiers()}, want: `multiple visibility modifiers: "public" and "internal"`},
+		{sig: "fallback(bytes memory a) returns (bytes memory b)", opts: []Option{WithStrictFallback()}, want: `fallback input must be "bytes calldata"`},
+		{sig: "fallback(bytes a) returns (bytes memory b)", opts: []Option{WithStrictFallback()}, want: `fallback input must be "bytes calldata"`},
+		{sig: "fallback(bytes calldata a) returns (bytes calldata b)", opts: []Option{WithStrictFallback()}, want: `fallback output must be "bytes memory"`},
+		{sig: "fallback(bytes calldata a) returns (bytes b)", opts: []Option{WithStrictFallback()}, want: `fallback output must be "bytes memory"`},
+		{sig: "event foo()", want: `event "foo" must declare at least one parameter`},
+		{sig: "event Transfer() anonymous", want: `event "Transfer" must declare at least one parameter`},
+		{sig: "event Foo()", want: `event "Foo" must declare at least one parameter`},
+		{sig: "event Foo() view", want: `event "Foo" must declare at least one parameter`},
+		{sig: "event Foo() anonymous anonymous", want: `event "Foo" must declare at least one parameter`},
+		{sig: "foo(Array[uint256])", want: `array size must be a number, got "uint256"`},
+		{sig: "foo(uint256[1a])", want: `array size must be a number, got "1a"`},
+		{sig: "foo(uint256[ 2 ])", want: `unexpected character ' ', ']' expected`},
+		{sig: "foo(uint256[2 ])", want: `unexpected character ' ', ']' expected`},
+		{sig: "foo(uint256[][N] a)", want: `array size must be a number, got "N"`},
+		{sig: "foo(int[ ])", want: `unexpected character ' ', ']' expected`},
+		{sig: "foo(uint256[, bool)", want: `unexpected character ',', ']' expected`},
+		{sig: "error Foo((uint256 indexed a) b)", want: `unexpected indexed flag in tuple element`},
+		{sig: "event Foo((uint256 indexed a) indexed b)", want: `unexpected indexed flag in tuple element`},
+		{sig: "event Foo(((uint256 indexed a)[] b) c)", want: `unexpected indexed flag in tuple element`},
+		{sig: "function foo((uint256 indexed a) b)", want: `unexpected indexed flag in tuple element`},
+		{sig: "foo((uint256 indexed a) b)", want: `unexpected indexed flag in tuple element`},
+		{sig: "foo() returns ((uint256 indexed a))", want: `unexpected indexed flag in tuple element`},
+		{sig: "error Foo(uint256 indexed a)", want: `unexpected indexed flag`},
+		{sig: "foo(int[2] [3])", want: `unexpected token after array dimension`},
+		{sig: "foo(int[2][3] [] a)", want: `unexpected token after array dimension`},
+		{sig: "event foo(int) returns (int)", want: `event signatures cannot declare return values`},
+		{sig: "event foo(int)(int)", want: `event signatures cannot declare return values`},
+		{sig: "  error foo(int)  returns (int)", want: `error signatures cannot declare return values`},
+		{sig: "foo((uint256 a, bool) b)", opts: []Option{WithRequireTupleFieldNames()}, want: `tuple field at index 1 requires a name`},
+		{sig: "foo() returns ((uint256 a, (bool)[] b))", opts: []Option{WithRequireTupleFieldNames()}, want: `tuple field at index 0 requires a name`},
+		{sig: "event Foo((uint256,uint256) memory a)", want: `unexpected data location "memory" in event input`},
+		{sig: "error Foo((uint256,uint256)[] calldata a)", want: `unexpected data location "calldata" in error input`},
+		{sig: "modifier foo() view", want: `modifier "view" not allowed on modifier`},
+		{sig: "modifier foo() returns (bool)", want: `modifier signatures cannot declare return values`},
+		{sig: "function foo() external view private", opts: []Option{WithStrictModifiers()}, want: `multiple visibility modifiers: "external" and "private"`},
+		{sig: "foo() payable view", opts: []Option{WithStrictModifiers()}, want: `conflicting state mutability modifiers "payable" and "view"`},
+		{sig: "foo() view view", opts: []Option{WithStrictModifiers()}, want: `duplicate modifier "view"`},
+		{sig: "foo() override(A) override(B)", opts: []Option{WithStrictModifiers()}, want: `duplicate modifier "override"`},
+		{sig: "foo() virtual private", opts: []Option{WithStrictModifiers()}, want: `modifier "virtual" not allowed on private function`},
+		{sig: "receive() external", opts: []Option{WithStrictModifiers()}, want: `receive function must be declared "external payable"`},
+		{sig: "fallback() public", opts: []Option{WithStrictModifiers()}, want: `fallback function must be declared "external"`},
+		{sig: "fallback() external pure", opts: []Option{WithStrictModifiers()}, want: `fallback function must be payable or non-payable, not "pure"`},
+		{sig: "function foo(uint256) anonymous", want: `modifier "anonymous" is only allowed on events`},
+		{sig: "returns (uint256)", want: `keyword "returns" cannot be used as a signature name`},
+		{sig: "function view()", want: `keyword "view" cannot be used as a signature name`},
+		{sig: "Foo(uint256 indexed a) anonymous anonymous", want: `duplicate modifier "anonymous"`},
+		{sig: "foo(function() private)", want: `function types cannot be private, only internal or external`},
+		{sig: "foo(function() external view payable)", want: `multiple state mutability modifiers in function type: "view" and "payable"`},
+		{sig: "foo(function() returns)", want: `'(' expected after 'returns' keyword in function type`},
+		{sig: "receive foo", want: `unexpected receive name "foo"`},
+		{sig: "fallback foo external", want: `unexpected fallback name "foo"`},
+		{sig: "foo(", want: `unclosed '('`},
+		{sig: "foo((", want: `unclosed '('`},
+		{sig: "foo((int a)", want: `unclosed '('`},
+		{sig: "foo()((", want: `unclosed '('`},
+		{sig: "foo(uint256 a, tuple(uint256 b", want: `unclosed '('`},
+		{sig: "foo(\n  (int a,\n   (int b)\n", want: `unclosed '('`},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			_, err := ParseSignature(tt.sig, tt.opts...)
+			if err == nil {
+				t.Fatalf("ParseSignature() expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("ParseSignature() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArrayDims(t *testing.T) {
+	tests := []struct {
+		dims    string
+		opts    []Option
+		want    []int
+		wantErr bool
+	}{
+		{dims: "", want: nil},
+		{dims: "[]", want: []int{-1}},
+		{dims: "[2][]", want: []int{2, -1}},
+		{dims: "[1][2][3]", want: []int{1, 2, 3}},
+		{dims: "[ 2 ] []", opts: []Option{WithLenientArrays()}, want: []int{2, -1}},
+		{dims: "[0]", wantErr: true},
+		{dims: "[-1]", wantErr: true},
+		{dims: "[99999999999999999999]", wantErr: true},
+		{dims: "[2", wantErr: true},
+		{dims: "[2]a", wantErr: true},
+		{dims: "[2] [3]", wantErr: true},
+		{dims: "uint256[2]", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseArrayDims(tt.dims, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseArrayDims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseArrayDims() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		opts    []Option
+		want    interface{}
+		wantErr bool
+	}{
+		{input: "foo", want: Parameter{Type: "foo"}},
+		{input: "foo", opts: []Option{WithPreferParameter()}, want: Parameter{Type: "foo"}},
+		{input: "foo", opts: []Option{WithPreferSignature()}, want: Signature{Name: "foo"}},
+		{input: "(int a, int b)", want: Parameter{Tuple: []Parameter{{Type: "int", Name: "a"}, {Type: "int", Name: "b"}}}},
+		{input: "foo(int)", want: Signature{Name: "foo", Inputs: []Parameter{{Type: "int"}}}},
+		{input: "foo(int)", opts: []Option{WithPreferSignature()}, want: Signature{Name: "foo", Inputs: []Parameter{{Type: "int"}}}},
+		{input: "int[2]", opts: []Option{WithPreferSignature()}, want: Parameter{Type: "int", Arrays: []int{2}}},
+		{input: "event foo(int indexed a)", want: Signature{Kind: EventKind, Name: "foo", Inputs: []Parameter{{Type: "int", Name: "a", Indexed: true}}}},
+		{input: "struct foo { int a; }", want: Parameter{Name: "foo", Tuple: []Parameter{{Type: "int", Name: "a"}}}},
+		{input: "foo(int", wantErr: true},
+		{input: "struct foo { int a }", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := Parse(tt.input, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDeeplyNested(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: strings.Repeat("(", 100000) + ")", wantErr: true},
+		{input: strings.Repeat("(", 100000) + strings.Repeat(")", 100000), wantErr: true},
+		{input: "foo" + strings.Repeat("(", 100000), wantErr: true},
+		{input: "foo(" + strings.Repeat("tuple(", 100000), wantErr: true},
+		{input: strings.Repeat("(", maxDepth) + strings.Repeat(")", maxDepth), wantErr: false},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			_, err := ParseSignature(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_, err = ParseParameter(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseParameter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			Kind(tt.input)
+		})
+	}
+}
+
 func TestParseParameter(t *testing.T) {
 	tests := []struct {
 		param   string
+		opts    []Option
 		want    Parameter
 		wantErr bool
 	}{
@@ -485,10 +1252,36 @@ func TestParseParameter(t *testing.T) {
 		{param: "int ", want: Parameter{Type: "int"}},
 		{param: " int  memory  a  ", want: Parameter{Type: "int", Name: "a", DataLocation: Memory}},
 		{param: "\nint[1]\na", want: Parameter{Type: "int", Arrays: []int{1}, Name: "a"}},
+		{param: "\r\nint\r\nmemory\r\na\r\n", want: Parameter{Type: "int", Name: "a", DataLocation: Memory}},
 		// Semicolons
 		{param: "int;", want: Parameter{Type: "int"}},
 		{param: "int;;", want: Parameter{Type: "int"}},
 		{param: "int ;; ", want: Parameter{Type: "int"}},
+		// Lenient arrays
+		{param: "int [1]", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{1}}},
+		{param: "int[ 1]", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{1}}},
+		{param: "int[1 ]", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{1}}},
+		{param: "int [ 1 ] a", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{1}, Name: "a"}},
+		{param: "int[ ][2] memory a", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{-1, 2}, Name: "a", DataLocation: Memory}},
+		{param: "(int, int) [ ]", opts: []Option{WithLenientArrays()}, want: Parameter{Tuple: []Parameter{{Type: "int"}, {Type: "int"}}, Arrays: []int{-1}}},
+		{param: "int[2] [3]", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{2, 3}}},
+		{param: "int[2]\t[3] [] a", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{2, 3, -1}, Name: "a"}},
+		{param: "int[2][3]", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Arrays: []int{2, 3}}},
+		{param: "int[2][3]", want: Parameter{Type: "int", Arrays: []int{2, 3}}},
+		{param: "int[2] [3]", wantErr: true},
+		{param: "int a", opts: []Option{WithLenientArrays()}, want: Parameter{Type: "int", Name: "a"}},
+		{param: "address payable", want: Parameter{Type: "address", Payable: true}},
+		{param: "address payable[] memory to", want: Parameter{Type: "address", Payable: true, Arrays: []int{-1}, DataLocation: Memory, Name: "to"}},
+		{param: "address  payable to", want: Parameter{Type: "address", Payable: true, Name: "to"}},
+		{param: "address payable_", want: Parameter{Type: "address", Name: "payable_"}},
+		{param: "uint256 payable", want: Parameter{Type: "uint256", Name: "payable"}},
+		{param: "(int a, bool b)[] c", opts: []Option{WithRequireTupleFieldNames()}, want: Parameter{Tuple: []Parameter{{Type: "int", Name: "a"}, {Type: "bool", Name: "b"}}, Arrays: []int{-1}, Name: "c"}},
+		{param: "()", opts: []Option{WithRequireTupleFieldNames()}, want: Parameter{}},
+		{param: "(int a, bool)", opts: []Option{WithRequireTupleFieldNames()}, wantErr: true},
+		{param: "(int a, bool)", want: Parameter{Tuple: []Parameter{{Type: "int", Name: "a"}, {Type: "bool"}}}},
+		{param: "int [1 a]", opts: []Option{WithLenientArrays()}, wantErr: true},
+		{param: "int [0]", opts: []Option{WithLenientArrays()}, wantErr: true},
+		{param: "int a [1]", opts: []Option{WithLenientArrays()}, wantErr: true},
 		// Invalid syntax
 		{param: "int[", wantErr: true},
 		{param: "int[1", wantErr: true},
@@ -508,7 +1301,7 @@ func TestParseParameter(t *testing.T) {
 	}
 	for n, tt := range tests {
 		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
-			got, err := ParseParameter(tt.param)
+			got, err := ParseParameter(tt.param, tt.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseParameter() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -527,6 +1320,13 @@ func TestParseStruct(t *testing.T) {
 		wantErr bool
 	}{
 		// Valid syntax
+		{param: "struct Task {function(uint256) external returns (bool) run; address owner;}", want: Parameter{
+			Name: "Task",
+			Tuple: []Parameter{
+				{Name: "run", Type: "function", Function: &FunctionType{Inputs: []Parameter{{Type: "uint256"}}, Outputs: []Parameter{{Type: "bool"}}, Visibility: External}},
+				{Name: "owner", Type: "address"},
+			},
+		}},
 		{param: "struct test {int a; int b;}", want: Parameter{
 			Name: "test",
 			Tuple: []Parameter{
@@ -540,6 +1340,12 @@ func TestParseStruct(t *testing.T) {
 				{Name: "a", Type: "int"}, {Name: "b", Type: "int"},
 			},
 		}},
+		{param: "struct test {\r\n\tint a;\r\n\tint b;\r\n}\r\n", want: Parameter{
+			Name: "test",
+			Tuple: []Parameter{
+				{Name: "a", Type: "int"}, {Name: "b", Type: "int"},
+			},
+		}},
 		// With array
 		{param: "struct test {int[1] a;}", want: Parameter{
 			Name: "test",
@@ -547,6 +1353,35 @@ func TestParseStruct(t *testing.T) {
 				{Name: "a", Type: "int", Arrays: []int{1}},
 			},
 		}},
+		{param: "struct test {uint256[] values; bytes32[4] ids; int[2][] m; address payable[] p; Point[][3] q;}", want: Parameter{
+			Name: "test",
+			Tuple: []Parameter{
+				{Name: "values", Type: "uint256", Arrays: []int{-1}},
+				{Name: "ids", Type: "bytes32", Arrays: []int{4}},
+				{Name: "m", Type: "int", Arrays: []int{2, -1}},
+				{Name: "p", Type: "address", Arrays: []int{-1}, Payable: true},
+				{Name: "q", Type: "Point", Arrays: []int{-1, 3}},
+			},
+		}},
+		// Nested struct and inline tuple
+		{param: "struct test {Point p; (int x, int y) q; tuple(int) r;}", want: Parameter{
+			Name: "test",
+			Tuple: []Parameter{
+				{Name: "p", Type: "Point"},
+				{Name: "q", Tuple: []Parameter{{Name: "x", Type: "int"}, {Name: "y", Type: "int"}}},
+				{Name: "r", Tuple: []Parameter{{Type: "int"}}},
+			},
+		}},
+		{param: "struct test {(int, bool)[] pairs; ((int a, bool b)[] x, int y)[2] z;}", want: Parameter{
+			Name: "test",
+			Tuple: []Parameter{
+				{Name: "pairs", Tuple: []Parameter{{Type: "int"}, {Type: "bool"}}, Arrays: []int{-1}},
+				{Name: "z", Tuple: []Parameter{
+					{Name: "x", Tuple: []Parameter{{Name: "a", Type: "int"}, {Name: "b", Type: "bool"}}, Arrays: []int{-1}},
+					{Name: "y", Type: "int"},
+				}, Arrays: []int{2}},
+			},
+		}},
 		// Empty struct
 		{param: "struct test {}", want: Parameter{Name: "test"}},
 		// Whitespaces
@@ -567,6 +1402,12 @@ func TestParseStruct(t *testing.T) {
 		{param: "struct test {int memory a;}", wantErr: true},
 		{param: "struct test {int a; int a;}[]", wantErr: true},
 		{param: "struct test {int a[]}", wantErr: true},
+		{param: "struct test {int[0] a;}", wantErr: true},
+		{param: "struct test {int[x] a;}", wantErr: true},
+		{param: "struct test {int[1 a;}", wantErr: true},
+		{param: "struct test {(int a;}", wantErr: true},
+		{param: "struct test {(int indexed a) b;}", wantErr: true},
+		{param: "struct test {(int, (bytes memory a)) b;}", wantErr: true},
 	}
 	for n, tt := range tests {
 		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
@@ -582,6 +1423,188 @@ func TestParseStruct(t *testing.T) {
 	}
 }
 
+func TestParseStructMappings(t *testing.T) {
+	tests := []struct {
+		param   string
+		opts    []Option
+		want    Parameter
+		wantErr string
+	}{
+		{
+			param:   "struct test {mapping(address => uint256) balances; uint256 total;}",
+			wantErr: `unexpected mapping field, mappings are not valid ABI types`,
+		},
+		{
+			param: "struct test {mapping(address => uint256) balances; uint256 total;}",
+			opts:  []Option{WithMappingPolicy(SkipMappings)},
+			want:  Parameter{Name: "test", Tuple: []Parameter{{Name: "total", Type: "uint256"}}},
+		},
+		{
+			param: "struct test {mapping(address => uint256) balances; uint256 total;}",
+			opts:  []Option{WithMappingPolicy(KeepMappings)},
+			want: Parameter{Name: "test", Tuple: []Parameter{
+				{Name: "balances", Type: "mapping", Mapping: &Mapping{Key: Parameter{Type: "address"}, Value: Parameter{Type: "uint256"}}},
+				{Name: "total", Type: "uint256"},
+			}},
+		},
+		{
+			param: "struct test {mapping (address owner => mapping(uint256 => Item[] items)) m;}",
+			opts:  []Option{WithMappingPolicy(KeepMappings)},
+			want: Parameter{Name: "test", Tuple: []Parameter{
+				{Name: "m", Type: "mapping", Mapping: &Mapping{
+					Key: Parameter{Name: "owner", Type: "address"},
+					Value: Parameter{Type: "mapping", Mapping: &Mapping{
+						Key:   Parameter{Type: "uint256"},
+						Value: Parameter{Name: "items", Type: "Item", Arrays: []int{-1}},
+					}},
+				}},
+			}},
+		},
+		{
+			param:   "struct test {mapping(uint256[] => bool) m;}",
+			opts:    []Option{WithMappingPolicy(SkipMappings)},
+			wantErr: `invalid mapping key type`,
+		},
+		{
+			param:   "struct test {mapping(uint256 a bool) m;}",
+			opts:    []Option{WithMappingPolicy(KeepMappings)},
+			wantErr: `unexpected character 'b', '=>' expected`,
+		},
+		{
+			param:   "struct test {mapping(uint256 => ) m;}",
+			opts:    []Option{WithMappingPolicy(KeepMappings)},
+			wantErr: `invalid mapping value type`,
+		},
+		{
+			param:   "struct test {mapping(uint256 => bool m;}",
+			opts:    []Option{WithMappingPolicy(KeepMappings)},
+			wantErr: `unexpected character ';', ')' expected`,
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseStruct(tt.param, tt.opts...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseStruct() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStruct() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStruct() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	str, err := ParseStruct("struct test {mapping(address a => mapping(uint256 => bool)) m;}", WithMappingPolicy(KeepMappings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := str.String(), "(mapping(address a => mapping(uint256 => bool)) m) test"; got != want {
+		t.Errorf("String() got = %v, want %v", got, want)
+	}
+}
+
+func TestSignatureParsedModifiers(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want []Modifier
+	}{
+		{sig: "foo()", want: nil},
+		{sig: "foo() external view", want: []Modifier{{Name: "external"}, {Name: "view"}}},
+		{
+			sig: "function foo() public view virtual override(A, B) onlyOwner() onlyRole(keccak256(\"ADMIN\"), msg.sender)",
+			want: []Modifier{
+				{Name: "public"},
+				{Name: "view"},
+				{Name: "virtual"},
+				{Name: "override", Args: []string{"A", "B"}},
+				{Name: "onlyOwner", Args: []string{}},
+				{Name: "onlyRole", Args: []string{`keccak256("ADMIN")`, "msg.sender"}},
+			},
+		},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			got := sig.ParsedModifiers
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParsedModifiers got = %#v, want %#v", got, tt.want)
+			}
+			if len(sig.Modifiers) != len(got) {
+				t.Fatalf("Modifiers got = %v, want %d names", sig.Modifiers, len(got))
+			}
+			for i, mod := range got {
+				if mod.Name != sig.Modifiers[i] {
+					t.Errorf("Modifiers[%d] got = %v, want %v", i, sig.Modifiers[i], mod.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSignatureOverrides(t *testing.T) {
+	tests := []struct {
+		sig       string
+		wantBases []string
+		wantOk    bool
+	}{
+		{sig: "foo() public view", wantBases: nil, wantOk: false},
+		{sig: "foo() public override", wantBases: nil, wantOk: true},
+		{sig: "foo() override(A)", wantBases: []string{"A"}, wantOk: true},
+		{sig: "function foo() public virtual override(A, IB.C) returns (uint256)", wantBases: []string{"A", "IB.C"}, wantOk: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			bases, ok := mustParseSignature(t, tt.sig).Overrides()
+			if ok != tt.wantOk {
+				t.Fatalf("Overrides() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(bases, tt.wantBases) {
+				t.Fatalf("Overrides() bases = %#v, want %#v", bases, tt.wantBases)
+			}
+		})
+	}
+}
+
+func TestParseUserDefinedType(t *testing.T) {
+	tests := []struct {
+		def     string
+		want    Parameter
+		wantErr string
+	}{
+		{def: "type Price is uint256", want: Parameter{Name: "Price", Type: "uint256"}},
+		{def: " type\tId is bytes32 ; ", want: Parameter{Name: "Id", Type: "bytes32"}},
+		{def: "type Wallet is address payable;", want: Parameter{Name: "Wallet", Type: "address", Payable: true}},
+		{def: "typeFoo is uint256", wantErr: `'type' keyword expected`},
+		{def: "type is uint256", wantErr: `unexpected character 'u', 'is' keyword expected`},
+		{def: "type Price uint256", wantErr: `unexpected character 'u', 'is' keyword expected`},
+		{def: "type Price is string", wantErr: `invalid underlying type, elementary value type expected`},
+		{def: "type Price is uint256[]", wantErr: `invalid underlying type, elementary value type expected`},
+		{def: "type Price is Amount", wantErr: `invalid underlying type, elementary value type expected`},
+		{def: "type Price is uint256)", wantErr: `unexpected character ')' at the end of the type declaration`},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseUserDefinedType(tt.def)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseUserDefinedType() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUserDefinedType() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseUserDefinedType() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSignatureString(t *testing.T) {
 	tests := []struct {
 		sig  Signature
@@ -594,6 +1617,8 @@ func TestSignatureString(t *testing.T) {
 		{sig: mustParseSignature(t, "receive()"), want: "receive()"},
 		{sig: mustParseSignature(t, "event foo(int)"), want: "event foo(int)"},
 		{sig: mustParseSignature(t, "error foo(int)"), want: "error foo(int)"},
+		{sig: mustParseSignature(t, "modifier foo"), want: "modifier foo()"},
+		{sig: mustParseSignature(t, "modifier foo(int a) virtual"), want: "modifier foo(int a) virtual"},
 		{sig: mustParseSignature(t, "foo(int)"), want: "foo(int)"},
 		{sig: mustParseSignature(t, "foo(int a, int b)"), want: "foo(int a, int b)"},
 		{sig: mustParseSignature(t, "foo(int[][1][2] a)"), want: "foo(int[][1][2] a)"},
@@ -608,8 +1633,19 @@ func TestSignatureString(t *testing.T) {
 		{sig: mustParseSignature(t, "foo(int storage a)"), want: "foo(int storage a)"},
 		{sig: mustParseSignature(t, "foo() internal pure"), want: "foo() internal pure"},
 		{sig: mustParseSignature(t, "foo() internal pure (int)"), want: "foo() internal pure returns (int)"},
+		{sig: mustParseSignature(t, "balanceOf(address) external view returns (uint256)"), want: "balanceOf(address) external view returns (uint256)"},
+		{sig: mustParseSignature(t, "foo() view external"), want: "foo() view external"},
 		{sig: mustParseSignature(t, "foo((int,int))"), want: "foo((int, int))"},
+		{sig: mustParseSignature(t, "foo() returns ((uint256 a, uint256 b)[2] c)"), want: "foo() returns ((uint256 a, uint256 b)[2] c)"},
+		{sig: mustParseSignature(t, "foo() returns (tuple(uint256 a, uint256 b) result)"), want: "foo() returns ((uint256 a, uint256 b) result)"},
+		{sig: mustParseSignature(t, "foo()((uint256 a,(bool x)[] b)[][3] memory c)"), want: "foo() returns ((uint256 a, (bool x)[] b)[][3] memory c)"},
 		{sig: mustParseSignature(t, "foo((int,int)[])"), want: "foo((int, int)[])"},
+		{sig: mustParseSignature(t, "foo() external onlyRole(ADMIN)"), want: "foo() external onlyRole(ADMIN)"},
+		{sig: Signature{Name: "foo", Modifiers: []string{"external", "onlyOwner"}}, want: "foo() external onlyOwner"},
+		{
+			sig:  Signature{Name: "foo", Modifiers: []string{"external"}, ParsedModifiers: []Modifier{{Name: "override", Args: []string{"A"}}}},
+			want: "foo() override(A)",
+		},
 	}
 	for n, tt := range tests {
 		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
@@ -620,6 +1656,318 @@ func TestSignatureString(t *testing.T) {
 	}
 }
 
+func TestSignatureStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		kind SignatureKind
+		sig  string
+		opts []Option
+	}{
+		{sig: "foo(int)"},
+		{sig: "function foo(int a) external view returns (int)"},
+		{sig: "constructor(int a)"},
+		{sig: "fallback(bytes calldata a) external returns (bytes memory)"},
+		{sig: "receive() external payable"},
+		{sig: "event foo(int indexed a, int b) anonymous"},
+		{sig: "error foo(int a)"},
+		{sig: "foo() returns ((uint256 a, uint256 b)[2] c)"},
+		{sig: "IERC20.transfer(address to, uint256 amount)", opts: []Option{WithScopedNames()}},
+		{sig: "transfer(address payable to, (address payable[2] a) b)"},
+		{sig: "function foo((uint256, uint256) memory a) returns ((uint256 b)[2] calldata)"},
+		{sig: "function foo() public view virtual override returns (uint256)"},
+		{sig: "function foo() external payable override(A, B) virtual returns (uint256)"},
+		{sig: "function foo() virtual override(A) pure"},
+		{kind: FunctionKind, sig: "foo(int)"},
+		{kind: EventKind, sig: "foo(int)"},
+		{kind: ErrorKind, sig: "foo(int)"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig, err := ParseSignatureAs(tt.kind, tt.sig, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseSignature(sig.String(), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, sig) {
+				t.Errorf("ParseSignature(Signature.String()) got = %v, want %v", got, sig)
+			}
+		})
+	}
+}
+
+func TestSignatureInterfaceFingerprint(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want string
+	}{
+		{sig: "foo(int a)", want: "foo(int a)"},
+		{sig: "foo(bytes memory a)", want: "foo(bytes a)"},
+		{sig: "foo(bytes calldata a)", want: "foo(bytes a)"},
+		{sig: "foo((bytes memory a, int[] storage b) calldata c)", want: "foo((bytes a, int[] b) c)"},
+		{sig: "function foo(string calldata a) external view returns (string memory b)", want: "function foo(string a) external view returns (string b)"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			str := sig.String()
+			if got := sig.InterfaceFingerprint(); got != tt.want {
+				t.Errorf("Signature.InterfaceFingerprint() = %v, want %v", got, tt.want)
+			}
+			if sig.String() != str {
+				t.Errorf("Signature.InterfaceFingerprint() modified the signature")
+			}
+		})
+	}
+}
+
+func TestSignatureLookup(t *testing.T) {
+	sig := mustParseSignature(t, "fill((address maker, (address token, uint256 amount)[] assets) order, uint256 fee)(bool ok, (uint256 a, uint256 b))")
+	tests := []struct {
+		path string
+		want Parameter
+		ok   bool
+	}{
+		{path: "fee", want: Parameter{Type: "uint256", Name: "fee"}, ok: true},
+		{path: "order.maker", want: Parameter{Type: "address", Name: "maker"}, ok: true},
+		{path: "order.assets.token", want: Parameter{Type: "address", Name: "token"}, ok: true},
+		{path: "order.1.1", want: Parameter{Type: "uint256", Name: "amount"}, ok: true},
+		{path: "0.0", want: Parameter{Type: "address", Name: "maker"}, ok: true},
+		{path: "ok", want: Parameter{Type: "bool", Name: "ok"}, ok: true},
+		{path: "order.taker", ok: false},
+		{path: "order.maker.x", ok: false},
+		{path: "order.5", ok: false},
+		{path: "order.-1", ok: false},
+		{path: "missing", ok: false},
+		{path: "", ok: false},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, ok := sig.Lookup(tt.path)
+			if ok != tt.ok {
+				t.Fatalf("Signature.Lookup() ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Signature.Lookup() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterFieldAndAt(t *testing.T) {
+	param, err := ParseParameter("(uint256 a, (bool c) b, address)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := param.Field("a"); !ok || got.Type != "uint256" {
+		t.Errorf("Parameter.Field(a) got = %v, %v", got, ok)
+	}
+	if got, ok := param.Field("b"); !ok || len(got.Tuple) != 1 {
+		t.Errorf("Parameter.Field(b) got = %v, %v", got, ok)
+	}
+	if _, ok := param.Field(""); ok {
+		t.Errorf("Parameter.Field() must not match unnamed elements")
+	}
+	if got, ok := param.At(2); !ok || got.Type != "address" {
+		t.Errorf("Parameter.At(2) got = %v, %v", got, ok)
+	}
+	if _, ok := param.At(3); ok {
+		t.Errorf("Parameter.At(3) expected false")
+	}
+	if _, ok := (Parameter{Type: "uint256"}).At(0); ok {
+		t.Errorf("Parameter.At(0) on non-tuple expected false")
+	}
+}
+
+func TestSignatureIndexedCount(t *testing.T) {
+	tests := []struct {
+		sig        string
+		indexed    int
+		nonIndexed int
+	}{
+		{sig: "event foo(int a)", indexed: 0, nonIndexed: 1},
+		{sig: "event foo(int indexed a)", indexed: 1, nonIndexed: 0},
+		{sig: "event foo(int indexed a, int b, int indexed c)", indexed: 2, nonIndexed: 1},
+		{sig: "event foo(int indexed a, int indexed b, int indexed c, int d, int e)", indexed: 3, nonIndexed: 2},
+		{sig: "event foo(int indexed a, int indexed b, int indexed c, int indexed d) anonymous", indexed: 4, nonIndexed: 0},
+		{sig: "function foo(int a, int b)", indexed: 0, nonIndexed: 0},
+		{sig: "foo(int indexed a, int b)", indexed: 0, nonIndexed: 0},
+		{sig: "error foo(int a)", indexed: 0, nonIndexed: 0},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			if got := sig.IndexedCount(); got != tt.indexed {
+				t.Errorf("Signature.IndexedCount() = %v, want %v", got, tt.indexed)
+			}
+			if got := sig.NonIndexedCount(); got != tt.nonIndexed {
+				t.Errorf("Signature.NonIndexedCount() = %v, want %v", got, tt.nonIndexed)
+			}
+		})
+	}
+}
+
+func TestSignatureInferMutability(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want StateMutability
+	}{
+		{sig: "foo()", want: NonPayable},
+		{sig: "foo(uint256)", want: NonPayable},
+		{sig: "foo() returns (uint256)", want: View},
+		{sig: "foo() view returns (uint256)", want: View},
+		{sig: "foo() pure returns (uint256)", want: Pure},
+		{sig: "foo() payable returns (uint256)", want: Payable},
+		{sig: "foo() constant returns (uint256)", want: View},
+		{sig: "foo() external pure", want: Pure},
+		{sig: "foo() external", want: NonPayable},
+		{sig: "foo() payable", want: Payable},
+		{sig: "foo() nonpayable returns (uint256)", want: NonPayable},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			modifiers := append([]string(nil), sig.Modifiers...)
+			if got := sig.InferMutability(); got != tt.want {
+				t.Errorf("Signature.InferMutability() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(sig.Modifiers, modifiers) {
+				t.Errorf("Signature.InferMutability() modified the signature")
+			}
+		})
+	}
+}
+
+func TestFunctionFromTuple(t *testing.T) {
+	pair, err := ParseStruct("struct Pair { address a; address b; }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := ParseParameter("(uint256 id, bool active)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		input   Parameter
+		output  Parameter
+		want    string
+		wantErr bool
+	}{
+		{name: "getPair", output: pair, want: "function getPair() returns ((address a, address b) Pair)"},
+		{name: "pairOf", input: args, output: pair, want: "function pairOf(uint256 id, bool active) returns ((address a, address b) Pair)"},
+		{name: "setPair", input: Parameter{Tuple: []Parameter{pair}}, want: "function setPair((address a, address b) Pair)"},
+		{name: "foo", input: args, output: Parameter{Type: "uint256"}, want: "function foo(uint256 id, bool active) returns (uint256)"},
+		{name: "", output: pair, wantErr: true},
+		{name: "foo", input: Parameter{Type: "uint256"}, wantErr: true},
+		{name: "foo", input: Parameter{Tuple: args.Tuple, Arrays: []int{-1}}, wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig, err := FunctionFromTuple(tt.name, tt.input, tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FunctionFromTuple() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := sig.String(); got != tt.want {
+				t.Errorf("FunctionFromTuple() = %v, want %v", got, tt.want)
+			}
+			if _, err := ParseSignature(sig.String()); err != nil {
+				t.Errorf("FunctionFromTuple() returned a malformed signature: %v", err)
+			}
+		})
+	}
+}
+
+func TestParameterArrays(t *testing.T) {
+	param, err := ParseParameter("uint256[2][][3] a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := param.WithoutArrays(); got.Arrays != nil || got.Type != "uint256" || got.Name != "a" {
+		t.Errorf("Parameter.WithoutArrays() got = %v", got)
+	}
+	var (
+		sizes []int
+		elem  = param
+	)
+	for {
+		next, n, ok := elem.PopArray()
+		if !ok {
+			break
+		}
+		sizes = append(sizes, n)
+		elem = next
+	}
+	if !reflect.DeepEqual(sizes, []int{3, -1, 2}) {
+		t.Errorf("Parameter.PopArray() sizes = %v, want [3 -1 2]", sizes)
+	}
+	if !reflect.DeepEqual(elem, param.WithoutArrays()) {
+		t.Errorf("Parameter.PopArray() element = %v, want %v", elem, param.WithoutArrays())
+	}
+	rebuilt := elem.PushArray(2).PushArray(-1).PushArray(3)
+	if !reflect.DeepEqual(rebuilt, param) {
+		t.Errorf("Parameter.PushArray() got = %v, want %v", rebuilt, param)
+	}
+	if rebuilt.String() != "uint256[2][][3] a" {
+		t.Errorf("Parameter.PushArray() string = %v", rebuilt.String())
+	}
+	// The original parameter must not be modified.
+	popped, _, _ := param.PopArray()
+	popped.PushArray(5)
+	param.PushArray(7)
+	if !reflect.DeepEqual(param.Arrays, []int{2, -1, 3}) {
+		t.Errorf("original parameter modified: %v", param.Arrays)
+	}
+	tuple, err := ParseParameter("(uint256, bool)[]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, n, ok := tuple.PopArray(); !ok || n != -1 || got.Arrays != nil || len(got.Tuple) != 2 {
+		t.Errorf("Parameter.PopArray() on tuple got = %v, %v, %v", got, n, ok)
+	}
+}
+
+func TestStripKind(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantKind SignatureKind
+		want     string
+	}{
+		{input: "function foo(uint256)", wantKind: FunctionKind, want: "foo(uint256)"},
+		{input: "  function  foo()  ", wantKind: FunctionKind, want: "foo()  "},
+		{input: "constructor(uint256)", wantKind: ConstructorKind, want: "(uint256)"},
+		{input: "fallback() external", wantKind: FallbackKind, want: "() external"},
+		{input: "receive() external payable", wantKind: ReceiveKind, want: "() external payable"},
+		{input: "event Transfer(address indexed from)", wantKind: EventKind, want: "Transfer(address indexed from)"},
+		{input: "error Foo(uint256)", wantKind: ErrorKind, want: "Foo(uint256)"},
+		{input: "foo(uint256)", wantKind: UnknownKind, want: "foo(uint256)"},
+		{input: "functions()", wantKind: UnknownKind, want: "functions()"},
+		{input: "eventually(uint256)", wantKind: UnknownKind, want: "eventually(uint256)"},
+		{input: "error_1(uint256)", wantKind: UnknownKind, want: "error_1(uint256)"},
+		{input: "error", wantKind: UnknownKind, want: "error"},
+		{input: "error e", wantKind: UnknownKind, want: "error e"},
+		{input: "function", wantKind: UnknownKind, want: "function"},
+		{input: "function foo", wantKind: UnknownKind, want: "function foo"},
+		{input: "function[] foo", wantKind: UnknownKind, want: "function[] foo"},
+		{input: "", wantKind: UnknownKind, want: ""},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			kind, got := StripKind(tt.input)
+			if kind != tt.wantKind {
+				t.Errorf("StripKind() kind = %v, want %v", kind, tt.wantKind)
+			}
+			if got != tt.want {
+				t.Errorf("StripKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestKind(t *testing.T) {
 	tests := []struct {
 		input string
@@ -640,6 +1988,8 @@ func TestKind(t *testing.T) {
 		{input: "event foo(int)", kind: EventSignatureInput},
 		{input: "error", kind: TypeInput},
 		{input: "error foo", kind: TypeInput},
+		{input: "modifier foo()", kind: ModifierSignatureInput},
+		{input: "modifiers()", kind: FunctionSignatureInput},
 		{input: "(int, int)", kind: TupleInput},
 		{input: "(int, int)[]", kind: ArrayInput},
 		{input: "tuple", kind: TypeInput},
@@ -665,6 +2015,29 @@ func TestKind(t *testing.T) {
 		{input: " struct foo { int a ; int b ; } ", kind: StructDefinitionInput},
 		{input: " struct foo { int a ; int b ; } ; ", kind: StructDefinitionInput},
 
+		// JSON ABI:
+		{input: `[{"type":"function","name":"foo","inputs":[]}]`, kind: JSONABIInput},
+		{input: ` {"type":"event","name":"foo","inputs":[]} `, kind: JSONABIInput},
+		{input: "\n[]", kind: JSONABIInput},
+
+		// Interfaces and contracts:
+		{input: "interface IERC20 {\n  function totalSupply() external view returns (uint256);\n}", kind: InterfaceInput},
+		{input: "contract Token is ERC20 { uint256 private fee; function burn() external {} }", kind: ContractInput},
+		{input: "abstract contract Base {}", kind: ContractInput},
+		{input: "interface IERC20 { function totalSupply( }", kind: InvalidInput},
+
+		// Lists:
+		{input: "function foo()\nfunction bar(uint256)", kind: SignatureListInput},
+		{input: "foo()\nbar(uint256)", kind: SignatureListInput},
+		{input: "foo()\nbar()", kind: SignatureListInput},
+		{input: "foo(uint256)\nreturns (uint256)", kind: FunctionSignatureInput},
+		{input: "foo() external\nview", kind: FunctionSignatureInput},
+		{input: "foo() bar(uint256)", kind: FunctionSignatureInput}, // "bar(uint256)" is a modifier invocation
+		{input: "function foo(); event Bar(uint256)", kind: SignatureListInput},
+		{input: "struct Point { int x; int y; }\nfoo(Point p)", kind: DefinitionListInput},
+		{input: "struct A { int a; }\nstruct B { A a; }", kind: DefinitionListInput},
+		{input: "foo()\nbar(", kind: InvalidInput},
+
 		// Unexpected characters at the end:
 		{input: "int !", kind: InvalidInput},
 		{input: "int() !", kind: InvalidInput},
@@ -686,6 +2059,43 @@ func TestKind(t *testing.T) {
 	}
 }
 
+func TestKindCandidates(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []InputKind
+	}{
+		{input: "foo", want: []InputKind{TypeInput, FunctionSignatureInput}},
+		{input: "function foo", want: []InputKind{TypeInput, FunctionSignatureInput}},
+		{input: "foo()", want: []InputKind{FunctionSignatureInput}},
+		{input: "event foo(int)", want: []InputKind{EventSignatureInput}},
+		{input: "int[]", want: []InputKind{ArrayInput}},
+		{input: "(int, bool)", want: []InputKind{TupleInput, FunctionSignatureInput}},
+		{input: "struct foo { int a; }", want: []InputKind{StructDefinitionInput}},
+		{input: "interface IFoo { function foo() external; }", want: []InputKind{InterfaceInput}},
+		{input: "function foo()\nfunction bar(uint256)", want: []InputKind{SignatureListInput, FunctionSignatureInput, DefinitionListInput}},
+		{input: "struct A { int a; }\nfoo(A a)", want: []InputKind{DefinitionListInput}},
+		{input: "foo(uint256)\nreturns (uint256)", want: []InputKind{FunctionSignatureInput}},
+		{input: "foo() external\nview", want: []InputKind{FunctionSignatureInput}},
+		{input: `[{"type":"function","name":"foo","inputs":[]}]`, want: []InputKind{JSONABIInput}},
+		{input: "int !", want: nil},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got := KindCandidates(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KindCandidates() = %v, want %v", got, tt.want)
+			}
+			want := InvalidInput
+			if len(tt.want) > 0 {
+				want = tt.want[0]
+			}
+			if kind := Kind(tt.input); kind != want {
+				t.Errorf("Kind() = %v, want %v", kind, want)
+			}
+		})
+	}
+}
+
 func FuzzParseSignature(f *testing.F) {
 	for _, s := range []string{
 		"function",
@@ -710,6 +2120,7 @@ func FuzzParseSignature(f *testing.F) {
 		"$",
 		" ",
 		"\n",
+		strings.Repeat("(", 100000) + ")",
 	} {
 		f.Add(s)
 	}
@@ -735,6 +2146,7 @@ func FuzzParseParameter(f *testing.F) {
 		"$",
 		" ",
 		"\n",
+		strings.Repeat("(", 100000) + ")",
 	} {
 		f.Add(s)
 	}
@@ -774,3 +2186,74 @@ func mustParseSignature(t *testing.T, s string) Signature {
 	}
 	return sig
 }
+
+func TestParserReset(t *testing.T) {
+	var p Parser
+	inputs := []struct {
+		input string
+		want  Signature
+	}{
+		{input: "function transferFrom(address from, address to, uint256 amount)", want: Signature{
+			Kind:   FunctionKind,
+			Name:   "transferFrom",
+			Inputs: []Parameter{{Type: "address", Name: "from"}, {Type: "address", Name: "to"}, {Type: "uint256", Name: "amount"}},
+		}},
+		{input: "foo(int)", want: Signature{Name: "foo", Inputs: []Parameter{{Type: "int"}}}},
+		{input: "event bar(uint256 indexed a)", want: Signature{
+			Kind:   EventKind,
+			Name:   "bar",
+			Inputs: []Parameter{{Type: "uint256", Name: "a", Indexed: true}},
+		}},
+	}
+	var prev []Signature
+	for n, tt := range inputs {
+		p.Reset(tt.input)
+		got, err := p.ParseSignature()
+		if err != nil {
+			t.Fatalf("case-%d: ParseSignature() error = %v", n+1, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("case-%d: ParseSignature() got = %v, want %v", n+1, got, tt.want)
+		}
+		// Parsing the same input again must give the same result.
+		again, err := p.ParseSignature()
+		if err != nil || !reflect.DeepEqual(again, tt.want) {
+			t.Fatalf("case-%d: repeated ParseSignature() got = %v, %v", n+1, again, err)
+		}
+		prev = append(prev, got)
+	}
+	// Reusing the buffer must not affect previously returned values.
+	for n, tt := range inputs {
+		if !reflect.DeepEqual(prev[n], tt.want) {
+			t.Errorf("case-%d: result changed after Reset, got = %v, want %v", n+1, prev[n], tt.want)
+		}
+	}
+	p.Reset("(int a, int b)")
+	if got, err := p.ParseParameter(); err != nil || len(got.Tuple) != 2 {
+		t.Errorf("ParseParameter() got = %v, %v", got, err)
+	}
+	p.Reset("struct foo { int a; }")
+	if got, err := p.ParseStruct(); err != nil || got.Name != "foo" {
+		t.Errorf("ParseStruct() got = %v, %v", got, err)
+	}
+}
+
+// BenchmarkParseSignatureLongNames parses signatures in which every name is
+// n bytes long. The time per operation should grow linearly with n.
+func BenchmarkParseSignatureLongNames(b *testing.B) {
+	for _, n := range []int{1 << 8, 1 << 12, 1 << 16} {
+		name := strings.Repeat("a", n)
+		sig := "function " + name + "(" +
+			name + "[2][] memory " + name + ", " +
+			"(" + name + " " + name + ", " + name + "[] " + name + ")[] " + name +
+			") " + name + " returns (" + name + " " + name + ")"
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.SetBytes(int64(len(sig)))
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseSignature(sig); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package sigparser
+
+import "fmt"
+
+// ParseStateVariable parses a declaration of a public state variable and
+// returns the signature of the getter function generated for it by the
+// Solidity compiler, e.g. "uint256 public totalSupply" is parsed as
+// "function totalSupply() external view returns (uint256)".
+//
+// Getters of mappings and arrays take an argument for every mapping key
+// and array index, and return the value type, e.g. "mapping(address =>
+// uint256[]) public deposits" is parsed as "function deposits(address,
+// uint256) external view returns (uint256)". Names of the mapping keys and
+// values, if any, are used as the names of the getter parameters.
+//
+// The declaration may include the constant, immutable and override
+// keywords, an initializer and a trailing semicolon. An error is returned
+// if the variable is not public, because no getter is generated for it.
+//
+// Struct types are returned as they are written. Use
+// ParseStateVariableWithRegistry to return the struct members instead, as
+// the Solidity compiler does.
+func ParseStateVariable(declaration string, opts ...Option) (Signature, error) {
+	pp := NewParser(opts...)
+	pp.Reset(declaration)
+	p := &pp.p
+	sig, err := p.parseStateVariable()
+	if err != nil {
+		return Signature{}, p.parseError(err)
+	}
+	return sig, nil
+}
+
+// ParseStateVariableWithRegistry works like ParseStateVariable, but it also
+// resolves struct types using the given registry. If the getter returns
+// a struct, the members of the struct are returned as separate values,
+// except for arrays and mappings, which are omitted by the Solidity
+// compiler, e.g. for "Order[] public orders" the getter is
+// "function orders(uint256) external view returns (address maker, uint256
+// amount)". If the registry is nil, no types are resolved.
+func ParseStateVariableWithRegistry(declaration string, registry *Registry, opts ...Option) (Signature, error) {
+	sig, err := ParseStateVariable(declaration, opts...)
+	if err != nil {
+		return Signature{}, err
+	}
+	if registry == nil {
+		return sig, nil
+	}
+	if str, ok := registry.Lookup(sig.Outputs[0].Type); ok {
+		var members []Parameter
+		for _, field := range str.Tuple {
+			if field.Mapping != nil || len(field.Arrays) > 0 {
+				continue
+			}
+			members = append(members, field)
+		}
+		if len(members) == 0 {
+			return Signature{}, fmt.Errorf(`struct %q has no members that can be returned by the getter of %q`, str.Name, sig.Name)
+		}
+		sig.Outputs = members
+	}
+	return registry.Resolve(sig)
+}
+
+// parseStateVariable parses a state variable declaration and returns the
+// signature of its getter.
+func (p *parser) parseStateVariable() (Signature, error) {
+	p.parseWhitespace()
+	var (
+		err error
+		typ Parameter
+	)
+	switch {
+	case p.peekMapping():
+		typ, err = p.parseMapping()
+	case p.peekFunctionType():
+		typ, err = p.parseFunctionType()
+	case p.hasNext() && (isAlpha(p.peek()) || isIdentifierSymbol(p.peek())):
+		typ, err = p.parseElementaryType()
+	case !p.hasNext():
+		err = fmt.Errorf(`unexpected end of input, type expected`)
+	default:
+		err = fmt.Errorf(`unexpected character %q, type expected`, p.peek())
+	}
+	if err != nil {
+		return Signature{}, err
+	}
+	// Parse keywords and the variable name.
+	var (
+		name string
+		mods []string
+	)
+	for len(name) == 0 {
+		p.parseWhitespace()
+		word := string(p.parseName())
+		switch word {
+		case "":
+			if !p.hasNext() {
+				return Signature{}, fmt.Errorf(`unexpected end of input, variable name expected`)
+			}
+			return Signature{}, fmt.Errorf(`unexpected character %q, variable name expected`, p.peek())
+		case "public", "internal", "private", "constant", "immutable", "transient":
+			mods = append(mods, word)
+		case "override":
+			p.parseOverrideBases()
+		default:
+			name = word
+		}
+	}
+	vis, err := findVisibility(mods, p.opts.strictModifiers)
+	if err != nil {
+		return Signature{}, err
+	}
+	if vis != Public {
+		return Signature{}, fmt.Errorf(`state variable %q is not public, no getter is generated`, name)
+	}
+	// The initializer is not needed to generate the getter.
+	p.parseWhitespace()
+	if !p.readByte('=') {
+		p.readByte(';')
+		p.parseWhitespace()
+		if p.hasNext() {
+			return Signature{}, fmt.Errorf(`unexpected character %q at the end of the variable declaration`, p.peek())
+		}
+	}
+	sig := Signature{
+		Kind:            FunctionKind,
+		Name:            name,
+		Modifiers:       []string{"external", "view"},
+		ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+		StateMutability: View,
+		Visibility:      External,
+	}
+	sig.Inputs, typ = getterInputs(typ)
+	sig.Outputs = []Parameter{typ}
+	return sig, nil
+}
+
+// getterInputs returns the getter arguments for the given state variable
+// type, one for every mapping key and array index, and the type of the
+// value returned by the getter.
+func getterInputs(typ Parameter) ([]Parameter, Parameter) {
+	var inputs []Parameter
+	typ.Name = ""
+	for {
+		switch {
+		case len(typ.Arrays) > 0:
+			for range typ.Arrays {
+				inputs = append(inputs, Parameter{Type: "uint256"})
+			}
+			typ.Arrays = nil
+		case typ.Mapping != nil:
+			inputs = append(inputs, typ.Mapping.Key)
+			typ = typ.Mapping.Value
+		default:
+			return inputs, typ
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseStateVariable(t *testing.T) {
+	getter := func(name string, inputs []Parameter, output Parameter) Signature {
+		return Signature{
+			Kind:            FunctionKind,
+			Name:            name,
+			Inputs:          inputs,
+			Outputs:         []Parameter{output},
+			Modifiers:       []string{"external", "view"},
+			ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+			StateMutability: View,
+			Visibility:      External,
+		}
+	}
+	tests := []struct {
+		decl    string
+		opts    []Option
+		want    Signature
+		wantErr bool
+	}{
+		{decl: "uint256 public totalSupply", want: getter("totalSupply", nil, Parameter{Type: "uint256"})},
+		{decl: "uint256 public totalSupply;", want: getter("totalSupply", nil, Parameter{Type: "uint256"})},
+		{decl: `string public constant name = "Token";`, want: getter("name", nil, Parameter{Type: "string"})},
+		{decl: "address payable public immutable override(A, B) owner", want: getter("owner", nil, Parameter{Type: "address", Payable: true})},
+		{decl: "bytes32[] public roles", want: getter("roles", []Parameter{{Type: "uint256"}}, Parameter{Type: "bytes32"})},
+		{decl: "uint8[2][] public matrix", want: getter("matrix", []Parameter{{Type: "uint256"}, {Type: "uint256"}}, Parameter{Type: "uint8"})},
+		{decl: "mapping(address => uint256) public balanceOf", want: getter("balanceOf", []Parameter{{Type: "address"}}, Parameter{Type: "uint256"})},
+		{
+			decl: "mapping(address account => uint256[] amounts) public deposits",
+			want: getter("deposits", []Parameter{{Type: "address", Name: "account"}, {Type: "uint256"}}, Parameter{Type: "uint256", Name: "amounts"}),
+		},
+		{
+			decl: "mapping(address => mapping(uint256 => bool)) public allowed",
+			want: getter("allowed", []Parameter{{Type: "address"}, {Type: "uint256"}}, Parameter{Type: "bool"}),
+		},
+		{
+			decl: "mapping(address owner => mapping(address spender => uint256[2] amounts)) public allowances",
+			want: getter("allowances", []Parameter{{Type: "address", Name: "owner"}, {Type: "address", Name: "spender"}, {Type: "uint256"}}, Parameter{Type: "uint256", Name: "amounts"}),
+		},
+		{decl: "Order[] public orders", want: getter("orders", []Parameter{{Type: "uint256"}}, Parameter{Type: "Order"})},
+		{decl: "function(uint256) external public callback", want: getter("callback", nil, Parameter{Type: "function", Function: &FunctionType{Inputs: []Parameter{{Type: "uint256"}}, Visibility: External}})},
+		{decl: "uint256 totalSupply", wantErr: true},
+		{decl: "uint256 private totalSupply", wantErr: true},
+		{decl: "uint256 public private totalSupply", want: getter("totalSupply", nil, Parameter{Type: "uint256"})},
+		{decl: "uint256 public private totalSupply", opts: []Option{WithStrictModifiers()}, wantErr: true},
+		{decl: "uint256 private public totalSupply", wantErr: true},
+		{decl: "uint256 public", wantErr: true},
+		{decl: "uint256 public totalSupply foo", wantErr: true},
+		{decl: "", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseStateVariable(tt.decl, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStateVariable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStateVariable() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStateVariableErrorMessages(t *testing.T) {
+	tests := []struct {
+		decl string
+		opts []Option
+		want string
+	}{
+		{decl: "uint256 internal fee", want: `state variable "fee" is not public, no getter is generated`},
+		{decl: "uint256 public", want: `unexpected end of input, variable name expected`},
+		{decl: "uint256 public x y", want: `unexpected character 'y' at the end of the variable declaration`},
+		{decl: "uint256 public private x", opts: []Option{WithStrictModifiers()}, want: `multiple visibility modifiers: "public" and "private"`},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			_, err := ParseStateVariable(tt.decl, tt.opts...)
+			if err == nil {
+				t.Fatalf("ParseStateVariable() expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("ParseStateVariable() error = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStateVariableWithRegistry(t *testing.T) {
+	registry, err := NewRegistry(
+		mustParseStruct(t, "struct Asset { address token; uint256 amount; }"),
+		mustParseStruct(t, "struct Order { address maker; Asset asset; uint256[] fills; string memo; }"),
+		mustParseStruct(t, "struct Fills { uint256[] fills; }"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterType(Parameter{Name: "Price", Type: "uint128"}); err != nil {
+		t.Fatal(err)
+	}
+	asset := Parameter{Name: "asset", Tuple: []Parameter{{Type: "address", Name: "token"}, {Type: "uint256", Name: "amount"}}}
+	tests := []struct {
+		decl    string
+		want    Signature
+		wantErr bool
+	}{
+		{
+			decl: "Order[] public orders",
+			want: Signature{
+				Kind:            FunctionKind,
+				Name:            "orders",
+				Inputs:          []Parameter{{Type: "uint256"}},
+				Outputs:         []Parameter{{Type: "address", Name: "maker"}, asset, {Type: "string", Name: "memo"}},
+				Modifiers:       []string{"external", "view"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+				StateMutability: View,
+				Visibility:      External,
+			},
+		},
+		{
+			decl: "mapping(address => mapping(uint256 => Order)) public ordersOf",
+			want: Signature{
+				Kind:            FunctionKind,
+				Name:            "ordersOf",
+				Inputs:          []Parameter{{Type: "address"}, {Type: "uint256"}},
+				Outputs:         []Parameter{{Type: "address", Name: "maker"}, asset, {Type: "string", Name: "memo"}},
+				Modifiers:       []string{"external", "view"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+				StateMutability: View,
+				Visibility:      External,
+			},
+		},
+		{
+			decl: "mapping(Price => Price) public prices",
+			want: Signature{
+				Kind:            FunctionKind,
+				Name:            "prices",
+				Inputs:          []Parameter{{Type: "uint128"}},
+				Outputs:         []Parameter{{Type: "uint128"}},
+				Modifiers:       []string{"external", "view"},
+				ParsedModifiers: []Modifier{{Name: "external"}, {Name: "view"}},
+				StateMutability: View,
+				Visibility:      External,
+			},
+		},
+		{decl: "Fills public fills", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := ParseStateVariableWithRegistry(tt.decl, registry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStateVariableWithRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStateVariableWithRegistry() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package sigparser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IsDynamic returns true if the parameter is a dynamic type as defined by
+// the ABI specification. Dynamic types are bytes, string, unbounded arrays,
+// and fixed-size arrays or tuples containing any dynamic type.
+//
+// An error is returned if the parameter contains a type that cannot be
+// ABI encoded, e.g. an unresolved struct name.
+func (p Parameter) IsDynamic() (bool, error) {
+	var (
+		dynamic bool
+		err     error
+	)
+	if len(p.Type) > 0 {
+		dynamic, err = isDynamicElementaryType(p.Type)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		for _, c := range p.Tuple {
+			d, err := c.IsDynamic()
+			if err != nil {
+				return false, err
+			}
+			dynamic = dynamic || d
+		}
+	}
+	for _, n := range p.Arrays {
+		if n == -1 {
+			dynamic = true
+		}
+	}
+	return dynamic, nil
+}
+
+// InputsAreStatic returns true if all input parameters are static types.
+// Static inputs can be encoded without the head/tail encoding of dynamic
+// values.
+//
+// An error is returned if any input cannot be ABI encoded.
+func (s Signature) InputsAreStatic() (bool, error) {
+	static := true
+	for _, input := range s.Inputs {
+		dynamic, err := input.IsDynamic()
+		if err != nil {
+			return false, err
+		}
+		if dynamic {
+			static = false
+		}
+	}
+	return static, nil
+}
+
+// StaticEncodedLength returns the length in bytes of the ABI encoded inputs.
+// Every elementary value occupies a single 32-byte word, and fixed-size
+// arrays and tuples are encoded in place.
+//
+// An error is returned if any of the inputs is dynamic, because the length
+// of dynamic inputs depends on the encoded values, or if any input cannot
+// be ABI encoded.
+func (s Signature) StaticEncodedLength() (int, error) {
+	length := 0
+	for i, input := range s.Inputs {
+		dynamic, err := input.IsDynamic()
+		if err != nil {
+			return 0, err
+		}
+		if dynamic {
+			return 0, fmt.Errorf(`input %d is dynamic`, i)
+		}
+		length += staticEncodedLength(input)
+	}
+	return length, nil
+}
+
+// staticEncodedLength returns the length of the ABI encoded static
+// parameter.
+func staticEncodedLength(p Parameter) int {
+	length := 32
+	if len(p.Type) == 0 {
+		length = 0
+		for _, c := range p.Tuple {
+			length += staticEncodedLength(c)
+		}
+	}
+	for _, n := range p.Arrays {
+		length *= n
+	}
+	return length
+}
+
+// RequiresDataLocation returns true if the parameter is a reference type that
+// must have a data location when used in an external function signature,
+// i.e. an array, bytes, string or a tuple. Arrays of value types require a
+// data location as well.
+//
+// Type names that are not elementary types, such as unresolved struct, enum
+// or contract names, are assumed to be value types, because whether they
+// need a data location cannot be determined without their definition.
+func (p Parameter) RequiresDataLocation() bool {
+	if len(p.Arrays) > 0 || len(p.Type) == 0 {
+		return true
+	}
+	return p.Type == "bytes" || p.Type == "string"
+}
+
+// ElementaryTypes returns the sorted list of distinct elementary types used
+// in the signature inputs and outputs, including tuple elements. Types are
+// returned in their canonical form without array dimensions, e.g. "uint256"
+// for "uint[]". Type names that are not elementary types, such as unresolved
+// struct names, are skipped.
+func (s Signature) ElementaryTypes() []string {
+	seen := map[string]bool{}
+	collectElementaryTypes(s.Inputs, seen)
+	collectElementaryTypes(s.Outputs, seen)
+	types := make([]string, 0, len(seen))
+	for typ := range seen {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// collectElementaryTypes adds the canonical elementary types used in params
+// to the seen set.
+func collectElementaryTypes(params []Parameter, seen map[string]bool) {
+	for _, p := range params {
+		if len(p.Type) == 0 {
+			collectElementaryTypes(p.Tuple, seen)
+			continue
+		}
+		if typ, err := canonicalElementaryType(p.Type); err == nil {
+			seen[typ] = true
+		}
+	}
+}
+
+// Normalize returns a copy of the signature in which all alias types are
+// expanded to their canonical names, e.g. "uint" to "uint256", as described
+// in Parameter.Normalize. The original signature is not modified.
+func (s Signature) Normalize() Signature {
+	s.Inputs = normalizeParams(s.Inputs)
+	s.Outputs = normalizeParams(s.Outputs)
+	s.Modifiers = append([]string(nil), s.Modifiers...)
+	s.ParsedModifiers = append([]Modifier(nil), s.ParsedModifiers...)
+	return s
+}
+
+// Normalize returns a copy of the parameter in which all alias types are
+// expanded to their canonical names: "uint" to "uint256", "int" to "int256",
+// "byte" to "bytes1", "fixed" to "fixed128x18" and "ufixed" to
+// "ufixed128x18". Tuple elements are normalized as well. Names, data
+// locations and other attributes are preserved, and types that are not
+// valid elementary types are left unchanged.
+func (p Parameter) Normalize() Parameter {
+	if len(p.Type) > 0 {
+		if typ, err := canonicalElementaryType(p.Type); err == nil {
+			p.Type = typ
+		}
+	}
+	p.Tuple = normalizeParams(p.Tuple)
+	p.Arrays = append([]int(nil), p.Arrays...)
+	if p.Function != nil {
+		fn := *p.Function
+		fn.Inputs = normalizeParams(fn.Inputs)
+		fn.Outputs = normalizeParams(fn.Outputs)
+		p.Function = &fn
+	}
+	return p
+}
+
+// normalizeParams returns normalized copies of params.
+func normalizeParams(params []Parameter) []Parameter {
+	if params == nil {
+		return nil
+	}
+	normalized := make([]Parameter, len(params))
+	for i, p := range params {
+		normalized[i] = p.Normalize()
+	}
+	return normalized
+}
+
+// canonicalElementaryType returns the canonical ABI name of the given
+// elementary type, expanding aliases such as "uint" to "uint256". It returns
+// an error if the type is not a valid elementary type.
+func canonicalElementaryType(typ string) (string, error) {
+	if _, err := isDynamicElementaryType(typ); err != nil {
+		return "", err
+	}
+	switch typ {
+	case "uint":
+		return "uint256", nil
+	case "int":
+		return "int256", nil
+	case "byte":
+		return "bytes1", nil
+	case "fixed":
+		return "fixed128x18", nil
+	case "ufixed":
+		return "ufixed128x18", nil
+	}
+	return typ, nil
+}
+
+// isElementaryType returns true if typ is a valid elementary type.
+func isElementaryType(typ string) bool {
+	_, err := isDynamicElementaryType(typ)
+	return err == nil
+}
+
+// isDynamicElementaryType returns true if the given elementary type is
+// dynamic. It returns an error if the type is not a valid elementary type.
+func isDynamicElementaryType(typ string) (bool, error) {
+	switch typ {
+	case "bytes", "string":
+		return true, nil
+	case "address", "bool", "function", "uint", "int", "byte", "fixed", "ufixed":
+		return false, nil
+	}
+	switch {
+	case strings.HasPrefix(typ, "bytes"):
+		n, ok := parseTypeSize(typ[5:])
+		if ok && n >= 1 && n <= 32 {
+			return false, nil
+		}
+		if ok && n == 0 {
+			return false, fmt.Errorf(`invalid type %q: use "bytes" for dynamic byte arrays`, typ)
+		}
+	case strings.HasPrefix(typ, "uint"):
+		if isValidIntSize(typ[4:]) {
+			return false, nil
+		}
+	case strings.HasPrefix(typ, "int"):
+		if isValidIntSize(typ[3:]) {
+			return false, nil
+		}
+	case strings.HasPrefix(typ, "ufixed"):
+		if isValidFixedSize(typ[6:]) {
+			return false, nil
+		}
+	case strings.HasPrefix(typ, "fixed"):
+		if isValidFixedSize(typ[5:]) {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf(`unknown type %q`, typ)
+}
+
+// isValidIntSize returns true if s is a valid bit size of an integer type.
+func isValidIntSize(s string) bool {
+	n, ok := parseTypeSize(s)
+	return ok && n >= 8 && n <= 256 && n%8 == 0
+}
+
+// isValidFixedSize returns true if s is a valid "MxN" suffix of a fixed
+// point type. The number of decimal places N must be between 1 and 80.
+func isValidFixedSize(s string) bool {
+	idx := strings.IndexByte(s, 'x')
+	if idx < 0 {
+		return false
+	}
+	m, mOk := parseTypeSize(s[:idx])
+	n, nOk := parseTypeSize(s[idx+1:])
+	return mOk && nOk && m >= 8 && m <= 256 && m%8 == 0 && n > 0 && n <= 80
+}
+
+// parseTypeSize parses the numeric suffix of a type name. Leading zeros
+// and signs are not allowed.
+func parseTypeSize(s string) (int, bool) {
+	if len(s) == 0 || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,248 @@
+package sigparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParameterIsDynamic(t *testing.T) {
+	tests := []struct {
+		param   string
+		want    bool
+		wantErr bool
+	}{
+		{param: "uint256", want: false},
+		{param: "uint", want: false},
+		{param: "int8", want: false},
+		{param: "address", want: false},
+		{param: "bool", want: false},
+		{param: "bytes1", want: false},
+		{param: "bytes32", want: false},
+		{param: "fixed128x18", want: false},
+		{param: "ufixed", want: false},
+		{param: "function", want: false},
+		{param: "bytes", want: true},
+		{param: "string", want: true},
+		{param: "uint256[]", want: true},
+		{param: "uint256[2]", want: false},
+		{param: "uint256[2][]", want: true},
+		{param: "string[2]", want: true},
+		{param: "(uint256,address)", want: false},
+		{param: "(uint256,bytes)", want: true},
+		{param: "(uint256,address)[3]", want: false},
+		{param: "(uint256,(bool,string))[3]", want: true},
+		{param: "()", want: false},
+		{param: "Foo", wantErr: true},
+		{param: "bytes0", wantErr: true},
+		{param: "bytes33", wantErr: true},
+		{param: "uint7", wantErr: true},
+		{param: "uint264", wantErr: true},
+		{param: "uint08", wantErr: true},
+		{param: "fixed128x81", wantErr: true},
+		{param: "fixed128x0", wantErr: true},
+		{param: "fixed128", wantErr: true},
+		{param: "(uint256,Foo)", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			param, err := ParseParameter(tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := param.IsDynamic()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parameter.IsDynamic() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parameter.IsDynamic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterIsDynamicErrorMessages(t *testing.T) {
+	tests := []struct {
+		param string
+		want  string
+	}{
+		{param: "bytes0", want: `invalid type "bytes0": use "bytes" for dynamic byte arrays`},
+		{param: "(uint256, bytes0[])", want: `invalid type "bytes0": use "bytes" for dynamic byte arrays`},
+		{param: "bytes33", want: `unknown type "bytes33"`},
+		{param: "bytes00", want: `unknown type "bytes00"`},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			param, err := ParseParameter(tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = param.IsDynamic()
+			if err == nil {
+				t.Fatalf("Parameter.IsDynamic() expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Parameter.IsDynamic() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterRequiresDataLocation(t *testing.T) {
+	tests := []struct {
+		param string
+		want  bool
+	}{
+		{param: "uint256", want: false},
+		{param: "address", want: false},
+		{param: "bool", want: false},
+		{param: "bytes32", want: false},
+		{param: "function", want: false},
+		{param: "Foo", want: false},
+		{param: "bytes", want: true},
+		{param: "string", want: true},
+		{param: "uint256[]", want: true},
+		{param: "uint256[2]", want: true},
+		{param: "bytes32[2][]", want: true},
+		{param: "Foo[]", want: true},
+		{param: "(uint256,address)", want: true},
+		{param: "()", want: true},
+		{param: "bytes memory", want: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			param, err := ParseParameter(tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := param.RequiresDataLocation(); got != tt.want {
+				t.Errorf("Parameter.RequiresDataLocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureInputsAreStatic(t *testing.T) {
+	tests := []struct {
+		sig     string
+		want    bool
+		wantErr bool
+	}{
+		{sig: "foo()", want: true},
+		{sig: "foo(uint256,address)", want: true},
+		{sig: "foo(bytes)", want: false},
+		{sig: "foo(uint256,bytes)", want: false},
+		{sig: "foo((uint256,address) a, bool)", want: true},
+		{sig: "foo((uint256,string) a)", want: false},
+		{sig: "foo()(bytes)", want: true},
+		{sig: "foo(Foo)", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := mustParseSignature(t, tt.sig).InputsAreStatic()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Signature.InputsAreStatic() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Signature.InputsAreStatic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureElementaryTypes(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want []string
+	}{
+		{sig: "foo()", want: []string{}},
+		{sig: "foo(uint256)", want: []string{"uint256"}},
+		{sig: "foo(uint a, uint256 b) returns (uint[2])", want: []string{"uint256"}},
+		{sig: "foo(address, (bool, (bytes, byte)[])[] a) returns (string, int)", want: []string{"address", "bool", "bytes", "bytes1", "int256", "string"}},
+		{sig: "foo(Foo a, (Bar b, fixed c)) returns (Foo)", want: []string{"fixed128x18"}},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			if got := sig.ElementaryTypes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Signature.ElementaryTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureStaticEncodedLength(t *testing.T) {
+	tests := []struct {
+		sig     string
+		want    int
+		wantErr bool
+	}{
+		{sig: "foo()", want: 0},
+		{sig: "foo(uint256)", want: 32},
+		{sig: "transfer(address,uint256)", want: 64},
+		{sig: "foo(bool, bytes1, int8, function)", want: 128},
+		{sig: "foo(uint256[3])", want: 96},
+		{sig: "foo(uint256[2][3])", want: 192},
+		{sig: "foo((uint256, address))", want: 64},
+		{sig: "foo((uint256, (bool, bytes32)[2])[3], uint8)", want: 512},
+		{sig: "foo(())", want: 0},
+		{sig: "foo(bytes)", wantErr: true},
+		{sig: "foo(uint256[])", wantErr: true},
+		{sig: "foo((uint256, string)[2])", wantErr: true},
+		{sig: "foo(Foo)", wantErr: true},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			got, err := mustParseSignature(t, tt.sig).StaticEncodedLength()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Signature.StaticEncodedLength() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Signature.StaticEncodedLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureNormalize(t *testing.T) {
+	tests := []struct {
+		sig  string
+		want string
+	}{
+		{sig: "foo()", want: "foo()"},
+		{sig: "foo(uint a, int b, byte c, fixed d, ufixed e)", want: "foo(uint256 a, int256 b, bytes1 c, fixed128x18 d, ufixed128x18 e)"},
+		{sig: "function foo(uint[2] memory a, (int b, Foo c)[] calldata d) view returns (uint)", want: "function foo(uint256[2] memory a, (int256 b, Foo c)[] calldata d) view returns (uint256)"},
+		{sig: "event Foo(uint indexed a, address payable b)", want: "event Foo(uint256 indexed a, address payable b)"},
+		{sig: "foo(uint8, bytes32, uint256x)", want: "foo(uint8, bytes32, uint256x)"},
+	}
+	for n, tt := range tests {
+		t.Run(fmt.Sprintf("case-%d", n+1), func(t *testing.T) {
+			sig := mustParseSignature(t, tt.sig)
+			if got := sig.Normalize().String(); got != tt.want {
+				t.Errorf("Signature.Normalize() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(sig, mustParseSignature(t, tt.sig)) {
+				t.Errorf("Signature.Normalize() modified the original signature")
+			}
+		})
+	}
+}
+
+func TestParameterNormalize(t *testing.T) {
+	param, err := ParseParameter("(uint a, (byte b)[] c)[2] memory d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Parameter{
+		Name:         "d",
+		Tuple:        []Parameter{{Type: "uint256", Name: "a"}, {Tuple: []Parameter{{Type: "bytes1", Name: "b"}}, Arrays: []int{-1}, Name: "c"}},
+		Arrays:       []int{2},
+		DataLocation: Memory,
+	}
+	if got := param.Normalize(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parameter.Normalize() = %#v, want %#v", got, want)
+	}
+	if param.Tuple[0].Type != "uint" {
+		t.Errorf("Parameter.Normalize() modified the original parameter")
+	}
+}